@@ -5,23 +5,76 @@ import (
 	"github.com/BattlesnakeOfficial/rules"
 	"github.com/BattlesnakeOfficial/rules/client"
 
-	// "github.com/samber/mo"
-	"fmt"
+	"context"
 	"log"
-	// "math"
+	"math"
+	"math/rand"
 	"slices"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/samber/lo"
 	"github.com/samber/lo/parallel"
+	"github.com/samber/mo"
 )
 
 // Update the SnakeAgent structure to include SnakeMetadataResponse
 type SnakeAgent struct {
-	Portfolio             HeuristicPortfolio
-	Metadata              client.SnakeMetadataResponse
-	Temperature           float64
+	Portfolio           HeuristicPortfolio
+	Metadata            client.SnakeMetadataResponse
+	Temperature         float64
 	LogPerformanceStats bool
+	SearchMode          SearchMode
+	MCTSIterations      int
+	ExplorationConstant float64
+	RolloutDepth        int
+	MaxSearchDepth      int
+	LatencyMargin       time.Duration
+	OpponentModel       OpponentModel
+	InteractionRadius   int
+	HeadToHead          HeadToHeadPolicy
+	TeamResolver        TeamResolver
+	TranspositionTable  *TranspositionTable
+	ReuseSearchTrees    bool
+	searchTreeReuse     mo.Option[bool]
+	searchTrees         *searchTreeCache
+	Rand                *rand.Rand
+	Seed                int64
+	seeded              bool
+	SeedFromGame        bool
+	randMu              sync.Mutex
+	MoveSelector        MoveSelector
+	TraceSink           TraceSink
+}
+
+// defaultMoveTimeout is used when a request does not specify the game timeout
+const defaultMoveTimeout = 500 * time.Millisecond
+
+// minMoveBudget is the least time given to choosing a move, even when the
+// latency margin leaves less of the timeout than that
+const minMoveBudget = 20 * time.Millisecond
+
+// SearchMode selects the algorithm ChooseMove uses to score candidate moves
+type SearchMode int
+
+const (
+	// SearchOnePly averages heuristic scores over every next state one move ahead
+	SearchOnePly SearchMode = iota
+	// SearchMCTS runs a portfolio-guided Monte Carlo Tree Search
+	SearchMCTS
+	// SearchExpectimax runs an iterative-deepening expectimax search
+	SearchExpectimax
+)
+
+func (m SearchMode) String() string {
+	switch m {
+	case SearchMCTS:
+		return "mcts"
+	case SearchExpectimax:
+		return "expectimax"
+	default:
+		return "one-ply"
+	}
 }
 
 // SnakeAgentOption defines a function type for configuring a SnakeAgent
@@ -47,12 +100,167 @@ func WithPerformanceLogging(enabled bool) SnakeAgentOption {
 	}
 }
 
+// WithMCTS switches the agent to Monte Carlo Tree Search with the given number of iterations per move.
+// An iteration count of 0 searches until the move deadline.
+func WithMCTS(iterations int) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.SearchMode = SearchMCTS
+		sa.MCTSIterations = iterations
+	}
+}
+
+// WithExplorationConstant sets the PUCT exploration constant used by MCTS
+func WithExplorationConstant(c float64) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.ExplorationConstant = c
+	}
+}
+
+// WithRolloutDepth sets how many random turns MCTS plays out before evaluating a new leaf
+func WithRolloutDepth(depth int) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.RolloutDepth = depth
+	}
+}
+
+// WithExpectimax switches the agent to iterative-deepening expectimax search up to maxDepth plies.
+// A maxDepth of 0 deepens until the move deadline.
+func WithExpectimax(maxDepth int) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.SearchMode = SearchExpectimax
+		sa.MaxSearchDepth = maxDepth
+	}
+}
+
+// WithOpponentModel sets how the agent weighs the other snakes' possible responses to its moves
+func WithOpponentModel(model OpponentModel) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.OpponentModel = model
+	}
+}
+
+// WithInteractionRadius sets the head distance beyond which other snakes are
+// simulated with the opponent model's most likely move instead of branching
+// on every considered move. Expectimax widens the radius to at least twice
+// the remaining depth, the furthest a head-to-head can still happen from. A
+// radius of 0, the default, disables pruning.
+func WithInteractionRadius(radius int) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.InteractionRadius = radius
+	}
+}
+
+// WithHeadToHeadPolicy sets how the agent treats moves, its own and those
+// of the snakes it simulates, into cells an equal or longer enemy head could
+// also enter: excluded from the considered moves, or kept but made less
+// likely. Defaults to HeadToHeadIgnore.
+func WithHeadToHeadPolicy(policy HeadToHeadPolicy) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.HeadToHead = policy
+	}
+}
+
+// WithTeamResolver sets how the agent decides which snakes are its allies.
+// Defaults to matching colors.
+func WithTeamResolver(resolver TeamResolver) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.TeamResolver = resolver
+	}
+}
+
+// WithTranspositionTable sets the number of entries in the transposition table. A size of 0 disables it.
+func WithTranspositionTable(size int) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.TranspositionTable = nil
+		if size > 0 {
+			sa.TranspositionTable = NewTranspositionTable(size)
+		}
+	}
+}
+
+// WithSearchTreeReuse enables or disables carrying the MCTS tree over to the
+// next turn of a game. Reuse is on by default with MCTS; enabling it for
+// another search mode panics in NewSnakeAgent, as only MCTS keeps a tree.
+func WithSearchTreeReuse(enabled bool) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.searchTreeReuse = mo.Some(enabled)
+	}
+}
+
+// WithSeed makes the agent's random choices reproducible from the given seed.
+// A seeded agent caps MCTS and expectimax by work rather than time, searching
+// with the default iteration count or depth when none is set, so that a
+// replay searches as far as the original. A search the move deadline cuts
+// short still stops at a time-dependent point, so replays only reproduce
+// moves whose search finished within the deadline.
+func WithSeed(seed int64) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.Seed = seed
+		sa.seeded = true
+		sa.Rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithRand sets the random number generator the agent draws from
+func WithRand(rng *rand.Rand) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.Rand = rng
+	}
+}
+
+// WithSeedFromGame derives the randomness for each move from the seed, game ID,
+// snake ID and turn, so a single move can be reproduced from a log. As with
+// WithSeed, search is capped by work rather than time. Search tree reuse is
+// off by default with it, since a reused tree carries the searches of earlier
+// turns into the move.
+func WithSeedFromGame(enabled bool) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.SeedFromGame = enabled
+	}
+}
+
+// WithMoveSelector sets how the agent picks a move from the move scores.
+// Defaults to a softmax at the agent's temperature, or with MCTS, whose
+// scores are root visit shares, to a ProportionalSelector.
+func WithMoveSelector(selector MoveSelector) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.MoveSelector = selector
+	}
+}
+
+// WithTraceSink sets where the agent emits the decision trace of each move. Defaults to the text log.
+func WithTraceSink(sink TraceSink) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.TraceSink = sink
+	}
+}
+
+// WithLatencyMargin sets how much of the game timeout is reserved for network latency
+func WithLatencyMargin(margin time.Duration) SnakeAgentOption {
+	return func(sa *SnakeAgent) {
+		sa.LatencyMargin = margin
+	}
+}
+
 func NewSnakeAgent(portfolio HeuristicPortfolio, metadata client.SnakeMetadataResponse, opts ...SnakeAgentOption) *SnakeAgent {
 	sa := &SnakeAgent{
-		Portfolio:             portfolio,
-		Metadata:              metadata,
-		Temperature:           5.0,  // default temperature
+		Portfolio:           portfolio,
+		Metadata:            metadata,
+		Temperature:         5.0,  // default temperature
 		LogPerformanceStats: true, // default to true
+		SearchMode:          SearchOnePly,
+		MCTSIterations:      defaultMCTSIterations,
+		ExplorationConstant: 1.5,
+		RolloutDepth:        2,
+		MaxSearchDepth:      0,
+		LatencyMargin:       100 * time.Millisecond,
+		OpponentModel:       NewUniformOpponentModel(),
+		InteractionRadius:   0,
+		TeamResolver:        NewColorTeamResolver(),
+		TranspositionTable:  NewTranspositionTable(1 << 16),
+		searchTrees:         newSearchTreeCache(),
+		Rand:                rand.New(rand.NewSource(time.Now().UnixNano())),
+		TraceSink:           NewTextTraceSink(),
 	}
 
 	// Apply all options
@@ -60,6 +268,22 @@ func NewSnakeAgent(portfolio HeuristicPortfolio, metadata client.SnakeMetadataRe
 		opt(sa)
 	}
 
+	// A seeded search stops after a fixed amount of work, not at a time that
+	// varies from run to run
+	if sa.seeded || sa.SeedFromGame {
+		if sa.MCTSIterations <= 0 {
+			sa.MCTSIterations = defaultMCTSIterations
+		}
+		if sa.MaxSearchDepth <= 0 {
+			sa.MaxSearchDepth = defaultExpectimaxDepth
+		}
+	}
+
+	sa.ReuseSearchTrees = sa.searchTreeReuse.OrElse(sa.SearchMode == SearchMCTS && !sa.SeedFromGame)
+	if sa.ReuseSearchTrees && sa.SearchMode != SearchMCTS {
+		panic("Search tree reuse requires MCTS search")
+	}
+
 	return sa
 }
 
@@ -68,59 +292,198 @@ func NewSnakeAgentWithTemp(portfolio HeuristicPortfolio, temperature float64, me
 	return NewSnakeAgent(portfolio, metadata, WithTemperature(temperature))
 }
 
+// EndGame releases any state kept for a game that has finished
+func (sa *SnakeAgent) EndGame(gameID string) {
+	if sa.searchTrees != nil {
+		sa.searchTrees.endGame(gameID)
+	}
+}
+
+// Close releases what the agent holds open, such as a trace file. It should
+// be called once the agent will choose no more moves.
+func (sa *SnakeAgent) Close() error {
+	return closeTraceSinks(sa.TraceSink)
+}
+
+// NewGameSnapshot creates a snapshot of the request using the agent's team resolver
+func (sa *SnakeAgent) NewGameSnapshot(request *client.SnakeRequest) GameSnapshot {
+	return NewGameSnapshotWithTeams(request, sa.TeamResolver)
+}
+
+// MoveDeadline returns the time by which a move must be chosen for a request
+// received at the given time, given the game timeout in milliseconds. The
+// budget never drops below minMoveBudget, so a margin as large as the timeout
+// still leaves time to score the moves.
+func (sa *SnakeAgent) MoveDeadline(received time.Time, timeoutMillis int) time.Time {
+	timeout := time.Duration(timeoutMillis) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultMoveTimeout
+	}
+	return received.Add(max(timeout-sa.LatencyMargin, minMoveBudget))
+}
+
+// ChooseMove picks a move without a time budget
 func (sa *SnakeAgent) ChooseMove(snapshot GameSnapshot) client.MoveResponse {
+	return sa.ChooseMoveWithContext(context.Background(), snapshot)
+}
+
+// ChooseMoveWithContext picks a move, returning the best move found so far
+// once the context is done.
+func (sa *SnakeAgent) ChooseMoveWithContext(ctx context.Context, snapshot GameSnapshot) client.MoveResponse {
+	moveResponse, _ := sa.ChooseMoveWithTrace(ctx, snapshot)
+	return moveResponse
+}
+
+// ChooseMoveWithTrace picks a move like ChooseMoveWithContext and also returns
+// the trace of the decision, which is emitted to the agent's trace sink.
+func (sa *SnakeAgent) ChooseMoveWithTrace(ctx context.Context, snapshot GameSnapshot) (client.MoveResponse, DecisionTrace) {
+	start := time.Now()
 	you := snapshot.You()
-	consideredMoves := you.ConsideredMoves()
+	consideredMoves := sa.consideredMoves(you)
 
 	consideredMoveStrs := lo.Map(consideredMoves, func(move rules.SnakeMove, _ int) string { return move.Move })
 	slices.Sort(consideredMoveStrs)
-	log.Printf("\n\n ### Start Turn %d: Considered Moves = %v", snapshot.Turn(), consideredMoveStrs)
 
-	if sa.LogPerformanceStats {
-		defer func() {
-			log.Printf("### Performance Stats:")
-			for _, h := range sa.Portfolio {
-				micros, evals := h.GetAndResetStats()
-				if evals > 0 {
-					avgMicros := float64(micros) / float64(evals)
-					totalMillis := float64(micros) / 1000.0
-					log.Printf("###   %25s: %6d evals, %8.2f µs/eval, %8.2f ms total",
-						h.Name(), evals, avgMicros, totalMillis)
-				}
-			}
-		}()
+	trace := DecisionTrace{
+		GameID:          snapshot.GameID(),
+		SnakeID:         you.ID(),
+		Turn:            snapshot.Turn(),
+		SearchMode:      sa.SearchMode.String(),
+		ConsideredMoves: consideredMoveStrs,
 	}
 
 	// If only one move is available, return it immediately
 	if len(consideredMoveStrs) == 1 {
-		return client.MoveResponse{
-			Move:  consideredMoveStrs[0],
-			Shout: "I'm moving " + consideredMoveStrs[0],
+		trace.Probabilities = map[string]float64{consideredMoveStrs[0]: 1}
+		return sa.finishMove(trace, consideredMoveStrs[0], start)
+	}
+
+	rng := sa.moveRand(snapshot)
+
+	// slice of scores aligned with consideredMoveStrs
+	var scores []float64
+	switch sa.SearchMode {
+	case SearchMCTS:
+		scores = sa.mctsMoveScores(ctx, snapshot, consideredMoveStrs, rng, &trace)
+	case SearchExpectimax:
+		scores = sa.expectimaxMoveScores(ctx, snapshot, consideredMoveStrs, &trace)
+	default:
+		scores = sa.onePlyMoveScores(ctx, snapshot, consideredMoveStrs, &trace)
+	}
+	trace.Scores = finiteValues(consideredMoveStrs, scores)
+	trace.DeadlineReached = ctx.Err() != nil
+
+	selector := sa.moveSelector()
+	probs := selector.Probabilities(scores, snapshot.Turn())
+	if math.IsInf(lo.Max(scores), -1) {
+		// The deadline passed before any move was scored
+		probs = lo.Map(scores, func(_ float64, _ int) float64 {
+			return 1.0 / float64(len(scores))
+		})
+	}
+	probs = sa.deprioritizeContestedMoves(snapshot, consideredMoveStrs, probs)
+	trace.Selector = selector.Name()
+	trace.Probabilities = finiteValues(consideredMoveStrs, probs)
+
+	chosenMove := consideredMoveStrs[lib.SampleFromWeightsWithRand(probs, rng)]
+	return sa.finishMove(trace, chosenMove, start)
+}
+
+// finishMove completes the trace with the chosen move and timings and emits it
+func (sa *SnakeAgent) finishMove(trace DecisionTrace, chosenMove string, start time.Time) (client.MoveResponse, DecisionTrace) {
+	trace.ChosenMove = chosenMove
+	trace.Duration = time.Since(start)
+
+	if sa.LogPerformanceStats {
+		for _, h := range sa.Portfolio {
+			micros, evals := h.GetAndResetStats()
+			if evals > 0 {
+				trace.HeuristicStats = append(trace.HeuristicStats, HeuristicStats{
+					Name:        h.Name(),
+					Evaluations: evals,
+					Micros:      micros,
+				})
+			}
 		}
+		if sa.TranspositionTable != nil {
+			trace.TranspositionHits, trace.TranspositionMisses = sa.TranspositionTable.GetAndResetStats()
+		}
+	}
+
+	if sa.TraceSink != nil {
+		sa.TraceSink.Emit(trace)
 	}
 
+	shout := "I'm moving " + chosenMove
+	if shouter, ok := sa.TeamResolver.(TeamShouter); ok {
+		shout = shouter.Shout(trace.GameID, trace.SnakeID, trace.Turn)
+	}
+
+	return client.MoveResponse{
+		Move:  chosenMove,
+		Shout: shout,
+	}, trace
+}
+
+// moveSelector returns the configured MoveSelector. MCTS defaults to playing
+// moves in proportion to their root visits, other searches to a softmax at
+// the agent's temperature.
+func (sa *SnakeAgent) moveSelector() MoveSelector {
+	if sa.MoveSelector != nil {
+		return sa.MoveSelector
+	}
+	if sa.SearchMode == SearchMCTS {
+		return NewProportionalSelector()
+	}
+	return NewSoftmaxSelector(ConstantTemperature(sa.Temperature))
+}
+
+// onePlyMoveScores scores each move by combining every heuristic over all
+// opponent responses one move ahead.
+func (sa *SnakeAgent) onePlyMoveScores(ctx context.Context, snapshot GameSnapshot, consideredMoveStrs []string, trace *DecisionTrace) []float64 {
 	// map: move -> set(state snapshots)
-	nextStatesMap := make(map[string][]GameSnapshot)
+	// States are generated round-robin across moves so that a deadline
+	// leaves every move with a share of its states rather than none
+	combinationsMap := make(map[string][][]rules.SnakeMove)
 	for _, move := range consideredMoveStrs {
-		nextStatesMap[move] = sa.generateNextStates(snapshot, move)
+		if ctx.Err() != nil {
+			break
+		}
+		combinationsMap[move] = sa.nextMoveCombinations(snapshot, move, sa.InteractionRadius)
 	}
+	nextStatesMap := make(map[string][]GameSnapshot)
+	for i, remaining := 0, true; remaining && ctx.Err() == nil; i++ {
+		remaining = false
+		for _, move := range consideredMoveStrs {
+			if combinations := combinationsMap[move]; i < len(combinations) {
+				remaining = true
+				if nextState := applyMoves(snapshot, combinations[i]); nextState != nil {
+					nextStatesMap[move] = append(nextStatesMap[move], nextState)
+				}
+			}
+		}
+	}
+	trace.NextStates = lo.MapValues(nextStatesMap, func(states []GameSnapshot, _ string) int { return len(states) })
 
 	// slice of maps, for each heuristic, giving mapping: move -> aggScore
-	allScores := parallel.Map(sa.Portfolio, func(heuristic WeightedHeuristic, _ int) map[string]HeuristicScore {
-		return sa.weightedScoresForHeuristic(heuristic, nextStatesMap, consideredMoveStrs)
+	allScores := sa.weightedScoresForMoves(ctx, snapshot, nextStatesMap, consideredMoveStrs)
+
+	// Trace scores for each heuristic
+	trace.Heuristics = lo.Map(sa.Portfolio, func(heuristic WeightedHeuristic, i int) HeuristicTrace {
+		return HeuristicTrace{
+			Name:       heuristic.Name(),
+			Weight:     heuristic.Weight(),
+			Aggregator: heuristic.Aggregator().Name(),
+			Raw: finiteValues(consideredMoveStrs, lo.Map(consideredMoveStrs, func(move string, _ int) float64 {
+				return allScores[i][move].Raw
+			})),
+			Weighted: finiteValues(consideredMoveStrs, lo.Map(consideredMoveStrs, func(move string, _ int) float64 {
+				return allScores[i][move].Weighted
+			})),
+		}
 	})
 
-	// Log raw scores for each heuristic
-	for i, heuristic := range sa.Portfolio {
-		scores := allScores[i]
-		log.Printf("MoveScores for %25s: %s", heuristic.NameAndWeight(), strings.Join(lo.Map(consideredMoveStrs, func(move string, _ int) string {
-			return fmt.Sprintf("%s=%6.1f", move, scores[move].Raw)
-		}), ", "))
-	}
-
-	totalHeuristicWeight := lo.SumBy(sa.Portfolio, func(heuristic WeightedHeuristic) float64 {
-		return heuristic.Weight()
-	})
+	totalHeuristicWeight := sa.Portfolio.TotalWeight()
 
 	// slice of scores aligned with consideredMoveStrs
 	normalizedScores := lo.Map(consideredMoveStrs, func(move string, _ int) float64 {
@@ -129,90 +492,202 @@ func (sa *SnakeAgent) ChooseMove(snapshot GameSnapshot) client.MoveResponse {
 		})
 	})
 
-	probs := lib.SoftmaxWithTemp(normalizedScores, sa.Temperature)
+	return normalizedScores
+}
 
-	log.Printf("### %36s: %s", "Normalized Weights", strings.Join(lo.Map(consideredMoveStrs, func(move string, i int) string {
-		return fmt.Sprintf("%s=%6.1f", move, normalizedScores[i])
-	}), ", "))
-	log.Printf("### %36s: %s", "Move Probabilities", strings.Join(lo.Map(consideredMoveStrs, func(move string, i int) string {
-		return fmt.Sprintf("%s=%5.1f%%", move, probs[i]*100)
-	}), ", "))
+// evaluatedStates holds the raw score of every heuristic for each next state
+// that was fully evaluated before the deadline
+type evaluatedStates struct {
+	states []GameSnapshot
+	scores [][]float64 // state index -> heuristic index -> raw score
+}
 
-	chosenMove := consideredMoveStrs[lib.SampleFromWeights(probs)]
+func (sa *SnakeAgent) evaluateStates(ctx context.Context, states []GameSnapshot) evaluatedStates {
+	// Parallelize state evaluation, skipping states once the deadline has passed
+	results := parallel.Map(states, func(state GameSnapshot, _ int) mo.Option[[]float64] {
+		if ctx.Err() != nil {
+			return mo.None[[]float64]()
+		}
+		scores := parallel.Map(sa.Portfolio, func(heuristic WeightedHeuristic, _ int) mo.Option[float64] {
+			if ctx.Err() != nil {
+				return mo.None[float64]()
+			}
+			return mo.Some(heuristic.F()(state))
+		})
+		if lo.SomeBy(scores, func(score mo.Option[float64]) bool { return score.IsAbsent() }) {
+			return mo.None[[]float64]()
+		}
+		return mo.Some(lo.Map(scores, func(score mo.Option[float64], _ int) float64 { return score.MustGet() }))
+	})
 
-	return client.MoveResponse{
-		Move:  chosenMove,
-		Shout: "I'm moving " + chosenMove,
+	var evaluated evaluatedStates
+	for i, result := range results {
+		if scores, ok := result.Get(); ok {
+			evaluated.states = append(evaluated.states, states[i])
+			evaluated.scores = append(evaluated.scores, scores)
+		}
 	}
+	return evaluated
 }
 
-func (sa *SnakeAgent) weightedScoresForHeuristic(heuristic WeightedHeuristic, nextStatesMap map[string][]GameSnapshot, consideredMoveStrs []string) map[string]HeuristicScore {
-	type moveScore struct {
-		move  string
-		score float64
-	}
+// values returns the portfolio value of each evaluated state
+func (e evaluatedStates) values(portfolio HeuristicPortfolio) []float64 {
+	totalWeight := portfolio.TotalWeight()
+	return lo.Map(e.scores, func(scores []float64, _ int) float64 {
+		value := 0.0
+		for h, heuristic := range portfolio {
+			value += scores[h] * heuristic.Weight() / totalWeight
+		}
+		return value
+	})
+}
 
-	scores := parallel.Map(consideredMoveStrs, func(move string, _ int) moveScore {
-		states := nextStatesMap[move]
-		// Parallelize state evaluation
-		stateScores := parallel.Map(states, func(state GameSnapshot, _ int) float64 {
-			return heuristic.F()(state)
+// weightedScoresForMoves scores every heuristic for every move with the
+// heuristic's aggregator, weighting the move's next states by the opponent
+// model's likelihood of each.
+func (sa *SnakeAgent) weightedScoresForMoves(ctx context.Context, snapshot GameSnapshot, nextStatesMap map[string][]GameSnapshot, consideredMoveStrs []string) []map[string]HeuristicScore {
+	// slice aligned with consideredMoveStrs of raw scores per heuristic
+	moveScores := parallel.Map(consideredMoveStrs, func(move string, _ int) []float64 {
+		evaluated := sa.evaluateStates(ctx, nextStatesMap[move])
+		if len(evaluated.states) == 0 {
+			// Moves with no evaluated states are ranked below every evaluated move
+			return lo.Map(sa.Portfolio, func(_ WeightedHeuristic, _ int) float64 { return math.Inf(-1) })
+		}
+		weights := sa.responseWeights(snapshot, evaluated.states, evaluated.values(sa.Portfolio))
+		return lo.Map(sa.Portfolio, func(heuristic WeightedHeuristic, h int) float64 {
+			stateScores := lo.Map(evaluated.scores, func(scores []float64, _ int) float64 { return scores[h] })
+			return heuristic.Aggregator().Aggregate(stateScores, weights)
 		})
-		mean := lo.Mean(stateScores)
-		return moveScore{
-			move:  move,
-			score: mean,
+	})
+
+	return lo.Map(sa.Portfolio, func(heuristic WeightedHeuristic, h int) map[string]HeuristicScore {
+		result := make(map[string]HeuristicScore)
+		for i, move := range consideredMoveStrs {
+			result[move] = HeuristicScore{
+				Raw:      moveScores[i][h],
+				Weighted: moveScores[i][h] * heuristic.Weight(),
+			}
 		}
+		return result
 	})
+}
 
-	result := make(map[string]HeuristicScore)
-	for _, score := range scores {
-		result[score.move] = HeuristicScore{
-			Raw:      score.score,
-			Weighted: score.score * heuristic.Weight(),
+func (sa *SnakeAgent) generateNextStates(ctx context.Context, snapshot GameSnapshot, move string, radius int) []GameSnapshot {
+	var nextStates []GameSnapshot
+	for _, moveSlice := range sa.nextMoveCombinations(snapshot, move, radius) {
+		if ctx.Err() != nil {
+			break
+		}
+		if nextState := applyMoves(snapshot, moveSlice); nextState != nil {
+			nextStates = append(nextStates, nextState)
 		}
 	}
+	// log.Printf("Generated next states: %+v", nextStates)
 
-	return result
+	return nextStates
 }
 
-func (sa *SnakeAgent) generateNextStates(snapshot GameSnapshot, move string) []GameSnapshot {
-	var nextStates []GameSnapshot
+// nextMoveCombinations returns every joint move of the alive snakes in which
+// we play move, branching only on the snakes within radius of our head
+func (sa *SnakeAgent) nextMoveCombinations(snapshot GameSnapshot, move string, radius int) [][]rules.SnakeMove {
 	yourID := snapshot.You().ID()
 
 	// Generate all possible move combinations for other snakes
 	presetMoves := map[string]rules.SnakeMove{yourID: {ID: yourID, Move: move}}
-	moveCombinations := generateConsideredMoveCombinations(snapshot.AliveSnakes(), presetMoves)
+	// Snakes too far away to reach us move without branching
+	for _, snake := range sa.distantSnakes(snapshot, radius) {
+		presetMoves[snake.ID()] = sa.representativeMove(snapshot, snake, move)
+	}
+	moveCombinations := generateConsideredMoveCombinations(snapshot.AliveSnakes(), presetMoves, sa.consideredMoves)
 
 	// log.Printf("Trying move %s, combinations: %v", move, getMoveComboList(moveCombinations))
 
-	for _, combination := range moveCombinations {
-		// Convert the combination map to a slice
-		var moveSlice []rules.SnakeMove
-		for _, m := range combination {
-			moveSlice = append(moveSlice, m)
-		}
+	// Convert the combination maps to slices in snake order, so a seeded
+	// search sees the same joint moves every time
+	return lo.Map(moveCombinations, func(combination map[string]rules.SnakeMove, _ int) []rules.SnakeMove {
+		return lo.Map(snapshot.AliveSnakes(), func(snake SnakeSnapshot, _ int) rules.SnakeMove {
+			return combination[snake.ID()]
+		})
+	})
+}
 
-		if snapshot == nil {
-			log.Fatalf("Snapshot is nil before applying moves")
-		}
-		nextState, err := snapshot.ApplyMoves(moveSlice)
+// applyMoves returns the state after the joint move, exiting on rule errors
+func applyMoves(snapshot GameSnapshot, moveSlice []rules.SnakeMove) GameSnapshot {
+	if snapshot == nil {
+		log.Fatalf("Snapshot is nil before applying moves")
+	}
+	nextState, err := snapshot.ApplyMoves(moveSlice)
+	if err != nil {
+		log.Fatalf("Error applying moves: %v", err)
+	}
+	return nextState
+}
+
+// evaluate returns the portfolio value of a snapshot, cached in the transposition table
+func (sa *SnakeAgent) evaluate(snapshot GameSnapshot) float64 {
+	if sa.TranspositionTable == nil {
+		return sa.Portfolio.Evaluate(snapshot)
+	}
+	if value, found := sa.TranspositionTable.Get(snapshot.Hash(), 0); found {
+		return value
+	}
+	value := sa.Portfolio.Evaluate(snapshot)
+	sa.TranspositionTable.Put(snapshot.Hash(), 0, value)
+	return value
+}
 
-		if err != nil {
-			log.Fatalf("Error applying moves: %v", err)
-		} else { // Debug the state after ApplyMoves call
-			// log.Printf("Next state after applying move: %+v", nextState)
+// distantSnakes returns the other alive snakes whose heads are further than
+// InteractionRadius from ours
+func (sa *SnakeAgent) distantSnakes(snapshot GameSnapshot, radius int) []SnakeSnapshot {
+	if radius <= 0 {
+		return nil
+	}
+	you := snapshot.You()
+	return lo.Filter(snapshot.AliveSnakes(), func(snake SnakeSnapshot, _ int) bool {
+		return snake.ID() != you.ID() && snapshot.Topology().Distance(snake.Head(), you.Head()) > radius
+	})
+}
+
+// representativeMove returns the considered move of a distant snake that the
+// opponent model rates most likely when we play move, holding every other
+// snake to its first considered move
+func (sa *SnakeAgent) representativeMove(snapshot GameSnapshot, snake SnakeSnapshot, move string) rules.SnakeMove {
+	candidates := sa.consideredMoves(snake)
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	yourID := snapshot.You().ID()
+	others := lo.FilterMap(snapshot.AliveSnakes(), func(other SnakeSnapshot, _ int) (rules.SnakeMove, bool) {
+		if other.ID() == yourID {
+			return rules.SnakeMove{ID: yourID, Move: move}, true
 		}
-		if nextState != nil {
-			nextStates = append(nextStates, nextState)
+		return sa.consideredMoves(other)[0], other.ID() != snake.ID()
+	})
+	var moves []rules.SnakeMove
+	var states []GameSnapshot
+	for _, candidate := range candidates {
+		state, err := snapshot.ApplyMoves(append(slices.Clone(others), candidate))
+		if err == nil {
+			moves = append(moves, candidate)
+			states = append(states, state)
 		}
 	}
-	// log.Printf("Generated next states: %+v", nextStates)
+	if len(states) == 0 {
+		return candidates[0]
+	}
 
-	return nextStates
+	values := lo.Map(states, func(state GameSnapshot, _ int) float64 { return sa.evaluate(state) })
+	weights := sa.responseWeights(snapshot, states, values)
+	return moves[lo.IndexOf(weights, lo.Max(weights))]
+}
+
+// consideredMoves returns the moves the agent considers for a snake
+func (sa *SnakeAgent) consideredMoves(snake SnakeSnapshot) []rules.SnakeMove {
+	return snake.ConsideredMoves(AvoidHeadToHead(sa.HeadToHead))
 }
 
-func generateConsideredMoveCombinations(snakes []SnakeSnapshot, presetMoves map[string]rules.SnakeMove) []map[string]rules.SnakeMove {
+func generateConsideredMoveCombinations(snakes []SnakeSnapshot, presetMoves map[string]rules.SnakeMove, consideredMoves func(SnakeSnapshot) []rules.SnakeMove) []map[string]rules.SnakeMove {
 	presetSnakeIDs := lo.Keys(presetMoves)
 
 	nonPresetSnakes := lo.Filter(snakes, func(snake SnakeSnapshot, _ int) bool {
@@ -225,7 +700,7 @@ func generateConsideredMoveCombinations(snakes []SnakeSnapshot, presetMoves map[
 	}
 
 	nonPresetMoves := lo.Map(nonPresetSnakes, func(snake SnakeSnapshot, _ int) []rules.SnakeMove {
-		return snake.ConsideredMoves()
+		return consideredMoves(snake)
 	})
 
 	moveCombinations := lib.CartesianProduct(nonPresetMoves...)
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestDistantSnakesMoveAsModelled(t *testing.T) {
+	// b is far from our head with food one step to its left
+	request := testRequest("standard", 11, 11,
+		testSnake("a", pt(1, 1), pt(1, 0), pt(0, 0)),
+		testSnake("b", pt(9, 5), pt(10, 5), pt(10, 4)))
+	request.Board.Food = []client.Coord{pt(7, 5)}
+	snapshot := NewGameSnapshot(request)
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+
+	tests := []struct {
+		name      string
+		opts      []SnakeAgentOption
+		wantMoves []string // b's moves across the combinations
+	}{
+		{"radius off by default", nil, []string{"down", "left", "up"}},
+		{"distant snake takes the model's move", []SnakeAgentOption{
+			WithInteractionRadius(2), WithOpponentModel(NewGreedyFoodOpponentModel()),
+		}, []string{"left"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{}, tt.opts...)
+			var bMoves []string
+			for _, combination := range sa.nextMoveCombinations(snapshot, "up", sa.InteractionRadius) {
+				for _, move := range combination {
+					if move.ID == "b" {
+						bMoves = append(bMoves, move.Move)
+					}
+				}
+			}
+			if !sameMoves(bMoves, tt.wantMoves) {
+				t.Errorf("b moves = %v, want %v", bMoves, tt.wantMoves)
+			}
+		})
+	}
+}
+
+func TestExpectimaxInteractionRadiusScalesWithDepth(t *testing.T) {
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+	tests := []struct {
+		radius, depth, want int
+	}{
+		{0, 3, 0},
+		{2, 1, 2},
+		{2, 3, 6},
+		{5, 2, 5},
+		{5, 3, 6},
+	}
+	for _, tt := range tests {
+		sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{}, WithInteractionRadius(tt.radius))
+		search := &expectimaxSearch{agent: sa}
+		if got := search.interactionRadius(tt.depth); got != tt.want {
+			t.Errorf("radius %d at depth %d = %d, want %d", tt.radius, tt.depth, got, tt.want)
+		}
+	}
+}
+
+func TestChooseMoveWithinDeadline(t *testing.T) {
+	// Eight snakes side by side give 3^7 joint moves for each of our moves,
+	// and every evaluation takes a millisecond
+	var snakes []client.Snake
+	for i := 0; i < 8; i++ {
+		x := 1 + 2*i
+		snakes = append(snakes, testSnake(fmt.Sprint("s", i), pt(x, 2), pt(x, 1), pt(x, 0)))
+	}
+	request := testRequest("standard", 19, 19, snakes...)
+	slow := NewPortfolio(NewHeuristic(1, "slow", func(snapshot GameSnapshot) float64 {
+		time.Sleep(time.Millisecond)
+		return lengthHeuristic(snapshot)
+	}))
+
+	searches := []struct {
+		name string
+		opts []SnakeAgentOption
+	}{
+		{"one-ply", nil},
+		{"mcts", []SnakeAgentOption{WithMCTS(0)}},
+		{"expectimax", []SnakeAgentOption{WithExpectimax(0)}},
+	}
+	budgets := []time.Duration{-time.Second, 20 * time.Millisecond}
+	const slack = 200 * time.Millisecond
+	for _, search := range searches {
+		for _, budget := range budgets {
+			t.Run(fmt.Sprint(search.name, "/", budget), func(t *testing.T) {
+				sa := NewSnakeAgent(slow, client.SnakeMetadataResponse{},
+					append([]SnakeAgentOption{WithTraceSink(NewMemoryTraceSink())}, search.opts...)...)
+				snapshot := sa.NewGameSnapshot(request)
+				ctx, cancel := context.WithTimeout(context.Background(), budget)
+				defer cancel()
+
+				start := time.Now()
+				response := sa.ChooseMoveWithContext(ctx, snapshot)
+				if elapsed := time.Since(start); elapsed > max(budget, 0)+slack {
+					t.Errorf("took %v with a budget of %v", elapsed, budget)
+				}
+				legal := snakeMovesToStrings(snapshot.You().ConsideredMoves())
+				if !slices.Contains(legal, response.Move) {
+					t.Errorf("move = %q, want one of %v", response.Move, legal)
+				}
+			})
+		}
+	}
+}
+
+// sameMoves reports whether got holds exactly the moves in want, in any order
+func sameMoves(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, move := range got {
+		counts[move]++
+	}
+	for _, move := range want {
+		counts[move]--
+		if counts[move] < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/Battle-Bunker/cyphid-snake/lib"
+)
+
+// ScoreAggregator reduces the scores a heuristic assigns to the next states
+// reachable by a move, weighted by their likelihood, to a single move score.
+type ScoreAggregator interface {
+	Name() string
+	Aggregate(scores []float64, weights []float64) float64
+}
+
+// MeanAggregator scores a move by the expected heuristic score
+type MeanAggregator struct{}
+
+func NewMeanAggregator() ScoreAggregator {
+	return MeanAggregator{}
+}
+
+func (MeanAggregator) Name() string {
+	return "mean"
+}
+
+func (MeanAggregator) Aggregate(scores []float64, weights []float64) float64 {
+	return lib.WeightedMean(scores, weights)
+}
+
+// MinAggregator scores a move by its worst possible outcome
+type MinAggregator struct{}
+
+func NewMinAggregator() ScoreAggregator {
+	return MinAggregator{}
+}
+
+func (MinAggregator) Name() string {
+	return "min"
+}
+
+func (MinAggregator) Aggregate(scores []float64, weights []float64) float64 {
+	return lib.WeightedMin(scores, weights)
+}
+
+// PercentileAggregator scores a move by a percentile of its outcomes,
+// e.g. 10 for the 10th percentile
+type PercentileAggregator struct {
+	Percentile float64
+}
+
+func NewPercentileAggregator(percentile float64) ScoreAggregator {
+	return PercentileAggregator{Percentile: percentile}
+}
+
+func (a PercentileAggregator) Name() string {
+	return fmt.Sprintf("p%g", a.Percentile)
+}
+
+func (a PercentileAggregator) Aggregate(scores []float64, weights []float64) float64 {
+	return lib.WeightedPercentile(scores, weights, a.Percentile/100)
+}
+
+// CVaRAggregator scores a move by the mean of its worst Percent percent of outcomes
+type CVaRAggregator struct {
+	Percent float64
+}
+
+func NewCVaRAggregator(percent float64) ScoreAggregator {
+	return CVaRAggregator{Percent: percent}
+}
+
+func (a CVaRAggregator) Name() string {
+	return fmt.Sprintf("cvar%g", a.Percent)
+}
+
+func (a CVaRAggregator) Aggregate(scores []float64, weights []float64) float64 {
+	return lib.WeightedCVaR(scores, weights, a.Percent/100)
+}
+
+// MeanMinusStdDevAggregator scores a move by its mean outcome penalized by K
+// standard deviations
+type MeanMinusStdDevAggregator struct {
+	K float64
+}
+
+func NewMeanMinusStdDevAggregator(k float64) ScoreAggregator {
+	return MeanMinusStdDevAggregator{K: k}
+}
+
+func (a MeanMinusStdDevAggregator) Name() string {
+	return fmt.Sprintf("mean-%gsd", a.K)
+}
+
+func (a MeanMinusStdDevAggregator) Aggregate(scores []float64, weights []float64) float64 {
+	return lib.WeightedMean(scores, weights) - a.K*lib.WeightedStdDev(scores, weights)
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAggregators(t *testing.T) {
+	// The outcome scoring 6 has no weight; the rest are 0, 4, 4 and 10
+	scores := []float64{10, 0, 4, 6}
+	weights := []float64{1, 1, 2, 0}
+	noWeights := []float64{0, 0, 0, 0}
+
+	tests := []struct {
+		aggregator ScoreAggregator
+		name       string
+		want       float64
+		unweighted float64
+	}{
+		{NewMeanAggregator(), "mean", 4.5, 5},
+		{NewMinAggregator(), "min", 0, 0},
+		{NewPercentileAggregator(10), "p10", 0, 0},
+		{NewPercentileAggregator(50), "p50", 4, 4},
+		{NewPercentileAggregator(100), "p100", 10, 10},
+		{NewCVaRAggregator(25), "cvar25", 0, 0},
+		{NewCVaRAggregator(50), "cvar50", 2, 2},
+		{NewMeanMinusStdDevAggregator(1), "mean-1sd", 4.5 - math.Sqrt(12.75), 5 - math.Sqrt(13)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.aggregator.Name(); got != tt.name {
+				t.Errorf("Name = %q, want %q", got, tt.name)
+			}
+			if got := tt.aggregator.Aggregate(scores, weights); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Aggregate = %v, want %v", got, tt.want)
+			}
+			// Without weights every outcome counts equally
+			if got := tt.aggregator.Aggregate(scores, noWeights); math.Abs(got-tt.unweighted) > 1e-9 {
+				t.Errorf("unweighted Aggregate = %v, want %v", got, tt.unweighted)
+			}
+		})
+	}
+}
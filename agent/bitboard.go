@@ -0,0 +1,235 @@
+package agent
+
+import (
+	"math/bits"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// MaxBitboardSize is the largest width and height a Bitboard can hold
+const MaxBitboardSize = 25
+
+// BitMask is a set of board cells with one uint64 per row; bit x of row y is
+// the cell at (x, y)
+type BitMask [MaxBitboardSize]uint64
+
+// Set adds p to the set, ignoring points outside the mask
+func (m *BitMask) Set(p rules.Point) {
+	if inMask(p) {
+		m[p.Y] |= 1 << p.X
+	}
+}
+
+// Clear removes p from the set, ignoring points outside the mask
+func (m *BitMask) Clear(p rules.Point) {
+	if inMask(p) {
+		m[p.Y] &^= 1 << p.X
+	}
+}
+
+// Has reports whether p is in the set; points outside the mask never are
+func (m BitMask) Has(p rules.Point) bool {
+	return inMask(p) && m[p.Y]&(1<<p.X) != 0
+}
+
+func inMask(p rules.Point) bool {
+	return p.X >= 0 && p.X < MaxBitboardSize && p.Y >= 0 && p.Y < MaxBitboardSize
+}
+
+func (m BitMask) Or(other BitMask) BitMask {
+	for y := range m {
+		m[y] |= other[y]
+	}
+	return m
+}
+
+func (m BitMask) And(other BitMask) BitMask {
+	for y := range m {
+		m[y] &= other[y]
+	}
+	return m
+}
+
+func (m BitMask) AndNot(other BitMask) BitMask {
+	for y := range m {
+		m[y] &^= other[y]
+	}
+	return m
+}
+
+func (m BitMask) Count() int {
+	count := 0
+	for _, row := range m {
+		count += bits.OnesCount64(row)
+	}
+	return count
+}
+
+func (m BitMask) IsEmpty() bool {
+	return m == BitMask{}
+}
+
+// Points lists the cells in the set, row by row
+func (m BitMask) Points() []rules.Point {
+	points := make([]rules.Point, 0, m.Count())
+	for y, row := range m {
+		for row != 0 {
+			x := bits.TrailingZeros64(row)
+			points = append(points, rules.Point{X: x, Y: y})
+			row &= row - 1
+		}
+	}
+	return points
+}
+
+// Bitboard is a packed alternative to Board for fast set-based searches.
+// Occupied holds every snake part and Vanishing the parts that will have
+// moved off by next turn, matching Cell.IsPassable. Wrapped is set when the
+// board edges connect, as in WrappedTopology.
+type Bitboard struct {
+	Width, Height int
+	Wrapped       bool
+	Occupied      BitMask
+	Vanishing     BitMask
+	Food          BitMask
+	Hazards       BitMask
+	Heads         BitMask
+	Snakes        map[string]BitMask // snake ID -> body
+}
+
+// NewBitboard packs the snapshot into a Bitboard, or returns nil if the board
+// is larger than MaxBitboardSize in either dimension
+func NewBitboard(g GameSnapshot) *Bitboard {
+	if g.Width() > MaxBitboardSize || g.Height() > MaxBitboardSize {
+		return nil
+	}
+	bb := &Bitboard{
+		Width:  g.Width(),
+		Height: g.Height(),
+		Snakes: make(map[string]BitMask, len(g.AliveSnakes())),
+	}
+	_, bb.Wrapped = g.Topology().(WrappedTopology)
+	full := bb.Full()
+
+	for _, food := range g.Food() {
+		if full.Has(food) {
+			bb.Food.Set(food)
+		}
+	}
+	for _, hazard := range g.Hazards() {
+		if full.Has(hazard) {
+			bb.Hazards.Set(hazard)
+		}
+	}
+
+	for _, snake := range g.AliveSnakes() {
+		body := snake.Body()
+		if len(body) == 0 {
+			continue
+		}
+		var mask BitMask
+		for _, p := range body {
+			if full.Has(p) {
+				mask.Set(p)
+			}
+		}
+		bb.Snakes[snake.ID()] = mask
+		bb.Occupied = bb.Occupied.Or(mask)
+		if full.Has(body[0]) {
+			bb.Heads.Set(body[0])
+		}
+
+		// Only an unstacked tail is gone by next turn, and never in modes where snakes always grow
+		tail := body[len(body)-1]
+		if len(body) > 1 && body[len(body)-2] != tail && !g.GameMode().SnakesAlwaysGrow {
+			bb.Vanishing.Set(tail)
+		}
+	}
+
+	return bb
+}
+
+func (bb *Bitboard) inBounds(p rules.Point) bool {
+	return p.X >= 0 && p.X < bb.Width && p.Y >= 0 && p.Y < bb.Height
+}
+
+// Full returns the set of every cell on the board
+func (bb *Bitboard) Full() BitMask {
+	var m BitMask
+	for y := 0; y < bb.Height; y++ {
+		m[y] = 1<<bb.Width - 1
+	}
+	return m
+}
+
+// Passable returns the cells a snake could move into next turn
+func (bb *Bitboard) Passable() BitMask {
+	return bb.Full().AndNot(bb.Occupied.AndNot(bb.Vanishing))
+}
+
+// Spread returns m together with every cell adjacent to it
+func (bb *Bitboard) Spread(m BitMask) BitMask {
+	rowMask := uint64(1)<<bb.Width - 1
+	last := bb.Width - 1
+	var out BitMask
+	for y := 0; y < bb.Height; y++ {
+		row := m[y] | m[y]<<1 | m[y]>>1
+		if y > 0 {
+			row |= m[y-1]
+		}
+		if y+1 < bb.Height {
+			row |= m[y+1]
+		}
+		if bb.Wrapped {
+			row |= m[y]>>last | (m[y]&1)<<last
+			row |= m[(y+bb.Height-1)%bb.Height] | m[(y+1)%bb.Height]
+		}
+		out[y] = row & rowMask
+	}
+	return out
+}
+
+// FloodFill returns the passable cells reachable from start, not counting
+// start itself unless it is passable and reachable
+func (bb *Bitboard) FloodFill(start rules.Point, passable BitMask) BitMask {
+	if !bb.inBounds(start) {
+		return BitMask{}
+	}
+	var origin BitMask
+	origin.Set(start)
+
+	reached := bb.Spread(origin).And(passable)
+	for {
+		next := bb.Spread(reached).And(passable)
+		if next == reached {
+			return reached
+		}
+		reached = next
+	}
+}
+
+// Distance returns the number of moves from start to target through passable
+// cells, or -1 if target cannot be reached
+func (bb *Bitboard) Distance(start, target rules.Point, passable BitMask) int {
+	if !bb.inBounds(start) || !bb.inBounds(target) {
+		return -1
+	}
+	if start == target {
+		return 0
+	}
+	var visited BitMask
+	visited.Set(start)
+	passable.Set(target)
+
+	for dist := 1; ; dist++ {
+		next := bb.Spread(visited).And(passable)
+		if next.Has(target) {
+			return dist
+		}
+		next = next.Or(visited)
+		if next == visited {
+			return -1
+		}
+		visited = next
+	}
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestBitMaskIgnoresPointsOutside(t *testing.T) {
+	for _, p := range []rules.Point{{X: -1, Y: 0}, {X: 0, Y: -1}, {X: MaxBitboardSize, Y: 0}, {X: 0, Y: MaxBitboardSize}} {
+		var m BitMask
+		m.Set(p)
+		m.Clear(p)
+		if m.Has(p) || !m.IsEmpty() {
+			t.Errorf("%v: mask = %v, want empty", p, m)
+		}
+	}
+}
+
+func TestNewBitboardSkipsOffBoardHead(t *testing.T) {
+	request := testRequest("standard", 7, 7,
+		testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)),
+		testSnake("b", pt(7, 5), pt(6, 5), pt(5, 5)))
+	bb := NewBitboard(NewGameSnapshot(request))
+
+	if want := (rules.Point{X: 3, Y: 3}); bb.Heads.Points()[0] != want || bb.Heads.Count() != 1 {
+		t.Errorf("heads = %v, want only %v", bb.Heads.Points(), want)
+	}
+}
+
+func TestBitboardFloodFill(t *testing.T) {
+	// b walls off x=2; its tail at (2, 0) leaves a gap unless stacked
+	a := testSnake("a", pt(0, 0), pt(1, 0))
+	wall := testSnake("b", pt(2, 4), pt(2, 3), pt(2, 2), pt(2, 1), pt(2, 0))
+	stackedWall := testSnake("b", pt(2, 4), pt(2, 3), pt(2, 2), pt(2, 1), pt(2, 0), pt(2, 0))
+
+	tests := []struct {
+		name    string
+		ruleset string
+		wall    client.Snake
+		want    int
+	}{
+		{"walled in", "standard", stackedWall, 9},
+		{"through the vanishing tail", "standard", wall, 9 + 1 + 10},
+		{"wrapped reaches around the wall", "wrapped", stackedWall, 9 + 10},
+		{"constrictor tails never free", "constrictor", wall, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bb := NewBitboard(NewGameSnapshot(testRequest(tt.ruleset, 5, 5, a, tt.wall)))
+			if got := bb.FloodFill(rules.Point{X: 0, Y: 0}, bb.Passable()).Count(); got != tt.want {
+				t.Errorf("reached %d cells, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package agent
 import (
 	"github.com/BattlesnakeOfficial/rules"
 	_ "log"
+	"math"
 )
 
 type CellKind int
@@ -21,9 +22,35 @@ type Cell interface {
 	Coordinates() rules.Point
 	Neighbours(board *Board) []Cell
 	PassableNeighbours(board *Board) []Cell
+	HazardStacks() int
+	HazardDamage() int
+	HealthCost() int
+	TurnsUntilFree() int
+}
+
+// hazardInfo is embedded in every cell to expose the hazard layer
+type hazardInfo struct {
+	stacks         int
+	damagePerStack int
+}
+
+// HazardStacks returns the number of hazards stacked on the cell
+func (h hazardInfo) HazardStacks() int {
+	return h.stacks
+}
+
+// HazardDamage returns the health lost to hazards by a snake whose head enters the cell
+func (h hazardInfo) HazardDamage() int {
+	return h.stacks * h.damagePerStack
+}
+
+// HealthCost returns the total health lost by moving into the cell
+func (h hazardInfo) HealthCost() int {
+	return 1 + h.HazardDamage()
 }
 
 type EmptyCell struct {
+	hazardInfo
 	coordinates rules.Point
 }
 
@@ -36,8 +63,12 @@ func (e EmptyCell) IsPassable() bool {
 func (e EmptyCell) Coordinates() rules.Point {
 	return e.coordinates
 }
+func (e EmptyCell) TurnsUntilFree() int {
+	return 0
+}
 
 type FoodCell struct {
+	hazardInfo
 	coordinates rules.Point
 }
 
@@ -50,6 +81,19 @@ func (f FoodCell) IsPassable() bool {
 func (f FoodCell) Coordinates() rules.Point {
 	return f.coordinates
 }
+func (f FoodCell) TurnsUntilFree() int {
+	return 0
+}
+
+// HazardDamage is always 0 because eating cancels hazard damage
+func (f FoodCell) HazardDamage() int {
+	return 0
+}
+
+// HealthCost is always 1 since eating restores health after the move
+func (f FoodCell) HealthCost() int {
+	return 1
+}
 
 // We can differentiate snake parts by a named type:
 type SnakePartType int
@@ -61,15 +105,26 @@ const (
 )
 
 type SnakePartCell struct {
+	hazardInfo
 	coordinates        rules.Point
 	SnakeID            string
 	PartType           SnakePartType
 	WillVanishNextTurn bool
+	turnsUntilFree     int
 }
 
 func (s SnakePartCell) Coordinates() rules.Point {
 	return s.coordinates
 }
+
+// NeverFree is the TurnsUntilFree of cells held by snakes that never shrink
+const NeverFree = math.MaxInt32
+
+// TurnsUntilFree returns how many moves it takes for the snake to pull its
+// body off this cell, assuming it does not eat in the meantime
+func (s SnakePartCell) TurnsUntilFree() int {
+	return s.turnsUntilFree
+}
 func (s SnakePartCell) Kind() CellKind {
 	switch s.PartType {
 	case SnakePartHead:
@@ -92,9 +147,8 @@ func getNeighbours(board *Board, pos rules.Point) []Cell {
 	neighbours := make([]Cell, 0, 4)
 
 	for _, d := range deltas {
-		newX, newY := pos.X+d.X, pos.Y+d.Y
-		if newX >= 0 && newX < board.Width && newY >= 0 && newY < board.Height {
-			neighbours = append(neighbours, board.Cells[newY][newX])
+		if next, ok := board.Topology.Step(pos, d); ok {
+			neighbours = append(neighbours, board.Cells[next.Y][next.X])
 		}
 	}
 	return neighbours
@@ -136,15 +190,46 @@ func (s SnakePartCell) PassableNeighbours(board *Board) []Cell {
 }
 
 type Board struct {
-	Width, Height int
-	Cells         [][]Cell
+	Width, Height       int
+	Cells               [][]Cell
+	HazardDamagePerTurn int
+	Topology            Topology
+}
+
+// IsPassableWithHealth reports whether a snake with the given health can move
+// into p and survive the hazard damage there
+func (b *Board) IsPassableWithHealth(p rules.Point, health int) bool {
+	cell := b.Cells[p.Y][p.X]
+	return cell.IsPassable() && cell.HealthCost() < health
+}
+
+// HealthCost returns the health lost by moving into p
+func (b *Board) HealthCost(p rules.Point) int {
+	return b.Cells[p.Y][p.X].HealthCost()
+}
+
+// IsPassableAfter reports whether a snake can move into p on its turns-th
+// move from now, i.e. whether any body occupying p will have moved off by then
+func (b *Board) IsPassableAfter(p rules.Point, turns int) bool {
+	return b.Cells[p.Y][p.X].TurnsUntilFree() <= turns
 }
 
 func NewBoard(g GameSnapshot) *Board {
 	board := &Board{
-		Width:  g.Width(),
-		Height: g.Height(),
-		Cells:  make([][]Cell, g.Height()),
+		Width:               g.Width(),
+		Height:              g.Height(),
+		Cells:               make([][]Cell, g.Height()),
+		HazardDamagePerTurn: g.Rules().Settings().Int(rules.ParamHazardDamagePerTurn, 0),
+		Topology:            g.Topology(),
+	}
+
+	// Count stacked hazards per cell
+	hazardStacks := make(map[rules.Point]int)
+	for _, hazard := range g.Hazards() {
+		hazardStacks[hazard]++
+	}
+	hazardAt := func(p rules.Point) hazardInfo {
+		return hazardInfo{stacks: hazardStacks[p], damagePerStack: board.HazardDamagePerTurn}
 	}
 
 	// Initialize cell slices
@@ -155,68 +240,49 @@ func NewBoard(g GameSnapshot) *Board {
 	// Place food
 	for _, food := range g.Food() {
 		if food.Y < g.Height() && food.X < g.Width() {
-			board.Cells[food.Y][food.X] = FoodCell{coordinates: food}
+			board.Cells[food.Y][food.X] = FoodCell{hazardInfo: hazardAt(food), coordinates: food}
 		}
 	}
 
 	// Place snakes
+	alwaysGrow := g.GameMode().SnakesAlwaysGrow
 	for _, snake := range g.AliveSnakes() {
 		body := snake.Body()
 		if len(body) == 0 {
 			continue
 		}
-
-		// if g.Turn() == 1 {
-		// 	log.Printf("Turn 1 - Snake %s body structure: %+v", snake.ID(), body)
-		// }
-
-		// Head
-		if body[0].Y < g.Height() && body[0].X < g.Width() {
-			cell := SnakePartCell{
-				coordinates:        body[0],
-				SnakeID:            snake.ID(),
-				PartType:           SnakePartHead,
-				WillVanishNextTurn: false,
+		tail := body[len(body)-1]
+
+		// A cell frees up once the segment nearest the head on it has been
+		// pulled off, which takes one move per segment behind it. Stacked tail
+		// segments (after eating, or at the start of the game) therefore
+		// stay for more than one turn.
+		for i, p := range body {
+			if p.Y >= g.Height() || p.X >= g.Width() {
+				continue
+			}
+			if existing, ok := board.Cells[p.Y][p.X].(SnakePartCell); ok && existing.SnakeID == snake.ID() {
+				continue // already placed by a segment nearer the head
 			}
-			// if g.Turn() == 1 {
-			// 	log.Printf("Turn 1 - Creating HEAD cell at (%d,%d): SnakeID=%s, PartType=%v, WillVanish=%v, SnakeLength=%d",
-			// 		cell.coordinates.X, cell.coordinates.Y, cell.SnakeID, cell.PartType, cell.WillVanishNextTurn, len(body))
-			// }
-			board.Cells[body[0].Y][body[0].X] = cell
-		}
 
-		// Body
-		for i := 1; i < len(body)-1; i++ {
-			if body[i].Y < g.Height() && body[i].X < g.Width() {
-				cell := SnakePartCell{
-					coordinates:        body[i],
-					SnakeID:            snake.ID(),
-					PartType:           SnakePartBody,
-					WillVanishNextTurn: false,
-				}
-				// if g.Turn() == 1 {
-				// 	log.Printf("Turn 1 - Creating BODY cell at (%d,%d): SnakeID=%s, PartType=%v, WillVanish=%v, SnakeLength=%d",
-				// 		cell.coordinates.X, cell.coordinates.Y, cell.SnakeID, cell.PartType, cell.WillVanishNextTurn, len(body))
-				// }
-				board.Cells[body[i].Y][body[i].X] = cell
+			partType := SnakePartBody
+			if i == 0 {
+				partType = SnakePartHead
+			} else if p == tail {
+				partType = SnakePartTail
 			}
-		}
 
-		// Tail
-		if len(body) > 1 {
-			tail := body[len(body)-1]
-			if tail.Y < g.Height() && tail.X < g.Width() {
-				cell := SnakePartCell{
-					coordinates:        tail,
-					SnakeID:            snake.ID(),
-					PartType:           SnakePartTail,
-					WillVanishNextTurn: snake.Health() < 100 && g.Turn() >= 3,
-				}
-				// if g.Turn() == 1 {
-				// 	log.Printf("Turn 1 - Creating TAIL cell at (%d,%d): SnakeID=%s, PartType=%v, WillVanish=%v, SnakeLength=%d",
-				// 		cell.coordinates.X, cell.coordinates.Y, cell.SnakeID, cell.PartType, cell.WillVanishNextTurn, len(body))
-				// }
-				board.Cells[tail.Y][tail.X] = cell
+			turnsUntilFree := len(body) - i
+			if alwaysGrow {
+				turnsUntilFree = NeverFree
+			}
+			board.Cells[p.Y][p.X] = SnakePartCell{
+				hazardInfo:         hazardAt(p),
+				coordinates:        p,
+				SnakeID:            snake.ID(),
+				PartType:           partType,
+				WillVanishNextTurn: turnsUntilFree == 1,
+				turnsUntilFree:     turnsUntilFree,
 			}
 		}
 	}
@@ -225,7 +291,8 @@ func NewBoard(g GameSnapshot) *Board {
 	for y := 0; y < g.Height(); y++ {
 		for x := 0; x < g.Width(); x++ {
 			if board.Cells[y][x] == nil {
-				board.Cells[y][x] = EmptyCell{coordinates: rules.Point{X: x, Y: y}}
+				p := rules.Point{X: x, Y: y}
+				board.Cells[y][x] = EmptyCell{hazardInfo: hazardAt(p), coordinates: p}
 			}
 		}
 	}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestBoardHazards(t *testing.T) {
+	request := testRequest("standard", 7, 7,
+		testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)),
+		testSnake("b", pt(5, 5), pt(5, 6), pt(6, 6)))
+	request.Board.Food = []client.Coord{pt(4, 4)}
+	request.Board.Hazards = []client.Coord{pt(0, 0), pt(1, 1), pt(1, 1), pt(3, 2), pt(4, 4)}
+	board := NewGameSnapshot(request).Board()
+
+	tests := []struct {
+		name   string
+		p      rules.Point
+		stacks int
+		damage int
+		cost   int
+	}{
+		{"clear", rules.Point{X: 6, Y: 0}, 0, 0, 1},
+		{"hazard", rules.Point{X: 0, Y: 0}, 1, 14, 15},
+		{"stacked hazards", rules.Point{X: 1, Y: 1}, 2, 28, 29},
+		{"hazard under a body", rules.Point{X: 3, Y: 2}, 1, 14, 15},
+		{"food cancels hazard damage", rules.Point{X: 4, Y: 4}, 1, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := board.Cells[tt.p.Y][tt.p.X]
+			if cell.HazardStacks() != tt.stacks || cell.HazardDamage() != tt.damage || board.HealthCost(tt.p) != tt.cost {
+				t.Errorf("stacks, damage, cost = %d, %d, %d, want %d, %d, %d",
+					cell.HazardStacks(), cell.HazardDamage(), board.HealthCost(tt.p), tt.stacks, tt.damage, tt.cost)
+			}
+		})
+	}
+
+	// Entering the stacked hazards takes 29 health
+	stacked := rules.Point{X: 1, Y: 1}
+	if board.IsPassableWithHealth(stacked, 29) || !board.IsPassableWithHealth(stacked, 30) {
+		t.Errorf("IsPassableWithHealth on stacked hazards = %v at 29 health, %v at 30, want false, true",
+			board.IsPassableWithHealth(stacked, 29), board.IsPassableWithHealth(stacked, 30))
+	}
+}
+
+func TestTurnsUntilFree(t *testing.T) {
+	// a has just eaten, stacking its tail; b's tail moves off next turn
+	request := testRequest("standard", 7, 7,
+		testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1), pt(3, 1)),
+		testSnake("b", pt(5, 5), pt(5, 4), pt(5, 3)))
+	board := NewGameSnapshot(request).Board()
+
+	tests := []struct {
+		name     string
+		p        rules.Point
+		want     int
+		passable bool
+	}{
+		{"head", rules.Point{X: 3, Y: 3}, 4, false},
+		{"middle of the body", rules.Point{X: 3, Y: 2}, 3, false},
+		{"stacked tail after eating", rules.Point{X: 3, Y: 1}, 2, false},
+		{"tail", rules.Point{X: 5, Y: 3}, 1, true},
+		{"empty", rules.Point{X: 0, Y: 0}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := board.Cells[tt.p.Y][tt.p.X]
+			if got := cell.TurnsUntilFree(); got != tt.want {
+				t.Errorf("TurnsUntilFree = %d, want %d", got, tt.want)
+			}
+			if cell.IsPassable() != tt.passable {
+				t.Errorf("IsPassable = %v, want %v", cell.IsPassable(), tt.passable)
+			}
+			if board.IsPassableAfter(tt.p, tt.want-1) || !board.IsPassableAfter(tt.p, tt.want) {
+				t.Errorf("IsPassableAfter %d turns = false or after %d = true", tt.want, tt.want-1)
+			}
+		})
+	}
+}
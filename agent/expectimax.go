@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+
+	"github.com/Battle-Bunker/cyphid-snake/lib"
+	"github.com/samber/lo"
+	"github.com/samber/lo/parallel"
+)
+
+// defaultExpectimaxDepth caps an uncapped search that has no deadline
+const defaultExpectimaxDepth = 2
+
+// expectimaxSearch maximizes over our moves and takes the expectation over
+// every combination of the other snakes' considered moves, weighted by the
+// agent's opponent model, evaluating leaves with the portfolio.
+type expectimaxSearch struct {
+	agent *SnakeAgent
+	nodes atomic.Int64
+}
+
+// moveValues returns the expected value of each move searched to the given
+// depth, and false if the context was done before the search completed.
+func (e *expectimaxSearch) moveValues(ctx context.Context, snapshot GameSnapshot, moves []string, depth int) ([]float64, bool) {
+	results := parallel.Map(moves, func(move string, _ int) lo.Tuple2[float64, bool] {
+		return lo.T2(e.moveValue(ctx, snapshot, move, depth))
+	})
+	values := lo.Map(results, func(r lo.Tuple2[float64, bool], _ int) float64 { return r.A })
+	complete := lo.EveryBy(results, func(r lo.Tuple2[float64, bool]) bool { return r.B })
+	return values, complete
+}
+
+// moveValue is the expected value of the next states reachable by our move.
+func (e *expectimaxSearch) moveValue(ctx context.Context, snapshot GameSnapshot, move string, depth int) (float64, bool) {
+	nextStates := e.agent.generateNextStates(ctx, snapshot, move, e.interactionRadius(depth))
+	if ctx.Err() != nil || len(nextStates) == 0 {
+		return 0, false
+	}
+	values := make([]float64, len(nextStates))
+	for i, state := range nextStates {
+		value, complete := e.stateValue(ctx, state, depth-1)
+		if !complete {
+			return 0, false
+		}
+		values[i] = value
+	}
+	weights := e.agent.responseWeights(snapshot, nextStates, values)
+	return lib.WeightedMean(values, weights), true
+}
+
+// interactionRadius is the agent's configured radius, widened to the head
+// distance within which snakes can still meet us in the remaining depth, or 0
+// when the agent does not prune
+func (e *expectimaxSearch) interactionRadius(depth int) int {
+	if e.agent.InteractionRadius <= 0 {
+		return 0
+	}
+	return max(e.agent.InteractionRadius, 2*depth)
+}
+
+// stateValue is the value of a state for us when we pick our best move.
+func (e *expectimaxSearch) stateValue(ctx context.Context, snapshot GameSnapshot, depth int) (float64, bool) {
+	e.nodes.Add(1)
+	if ctx.Err() != nil {
+		return 0, false
+	}
+	if depth <= 0 || !snapshot.You().Alive() {
+		return e.agent.evaluate(snapshot), true
+	}
+
+	table := e.agent.TranspositionTable
+	if table != nil {
+		if value, found := table.Get(snapshot.Hash(), depth); found {
+			return value, true
+		}
+	}
+
+	best := math.Inf(-1)
+	for _, move := range snakeMovesToStrings(e.agent.consideredMoves(snapshot.You())) {
+		value, complete := e.moveValue(ctx, snapshot, move, depth)
+		if !complete {
+			return 0, false
+		}
+		best = math.Max(best, value)
+	}
+	if table != nil {
+		table.Put(snapshot.Hash(), depth, best)
+	}
+	return best, true
+}
+
+// expectimaxMoveScores searches one ply deeper at a time until the depth cap
+// is reached or the context is done, and returns the move values from the
+// deepest completed search.
+func (sa *SnakeAgent) expectimaxMoveScores(ctx context.Context, snapshot GameSnapshot, consideredMoveStrs []string, trace *DecisionTrace) []float64 {
+	maxDepth := sa.MaxSearchDepth
+	if _, hasDeadline := ctx.Deadline(); maxDepth <= 0 && !hasDeadline {
+		maxDepth = defaultExpectimaxDepth
+	}
+
+	search := &expectimaxSearch{agent: sa}
+	reachedDepth := 0
+	values := lo.Map(consideredMoveStrs, func(_ string, _ int) float64 { return 0 })
+
+	for depth := 1; maxDepth <= 0 || depth <= maxDepth; depth++ {
+		depthValues, complete := search.moveValues(ctx, snapshot, consideredMoveStrs, depth)
+		if !complete {
+			break
+		}
+		values = depthValues
+		reachedDepth = depth
+	}
+
+	trace.SearchDepth = reachedDepth
+	trace.SearchNodes = search.nodes.Load()
+
+	return values
+}
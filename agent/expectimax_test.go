@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestExpectimaxAvoidsFoodTrap(t *testing.T) {
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+	tests := []struct {
+		depth int
+		want  string
+	}{
+		{1, "left"},
+		{2, "right"},
+		{3, "right"},
+	}
+	for _, tt := range tests {
+		sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{},
+			WithExpectimax(tt.depth), WithMoveSelector(NewArgmaxSelector()))
+		response, trace := sa.ChooseMoveWithTrace(context.Background(), sa.NewGameSnapshot(foodTrapRequest()))
+		if response.Move != tt.want {
+			t.Errorf("depth %d: move = %s, want %s (scores %v)", tt.depth, response.Move, tt.want, trace.Scores)
+		}
+		if trace.SearchDepth != tt.depth {
+			t.Errorf("depth %d: searched to depth %d", tt.depth, trace.SearchDepth)
+		}
+	}
+}
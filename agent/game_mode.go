@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/samber/lo"
+)
+
+// GameMode describes the rules of an official game mode that change how the
+// agent reads the board
+type GameMode struct {
+	Name string
+	// Wrapped boards connect opposite edges
+	Wrapped bool
+	// SnakesAlwaysGrow is set when snakes grow every turn, so their tails never move
+	SnakesAlwaysGrow bool
+	// HazardsShrink is set when the hazard zone closes in over time
+	HazardsShrink bool
+	// Solo games continue while a single snake is alive
+	Solo bool
+}
+
+var gameModes = map[string]GameMode{
+	rules.GameTypeStandard:           {Name: rules.GameTypeStandard},
+	rules.GameTypeSolo:               {Name: rules.GameTypeSolo, Solo: true},
+	rules.GameTypeRoyale:             {Name: rules.GameTypeRoyale, HazardsShrink: true},
+	rules.GameTypeConstrictor:        {Name: rules.GameTypeConstrictor, SnakesAlwaysGrow: true},
+	rules.GameTypeWrapped:            {Name: rules.GameTypeWrapped, Wrapped: true},
+	rules.GameTypeWrappedConstrictor: {Name: rules.GameTypeWrappedConstrictor, Wrapped: true, SnakesAlwaysGrow: true},
+}
+
+// simulatedShrinkStages are the royale stages without the hazard shrink.
+// The engine regenerates the whole hazard zone with a random side for every
+// shrink, so simulating it would invent hazards the real game never has;
+// simulated turns keep the current hazards and ForecastHazards estimates the
+// shrinks instead.
+func simulatedShrinkStages(solo bool) []string {
+	return []string{
+		lo.Ternary(solo, rules.StageGameOverSoloSnake, rules.StageGameOverStandard),
+		rules.StageMovementStandard,
+		rules.StageStarvationStandard,
+		rules.StageHazardDamageStandard,
+		rules.StageFeedSnakesStandard,
+		rules.StageEliminationStandard,
+	}
+}
+
+// GameModeFor returns the game mode of the named ruleset, falling back to
+// standard rules for unknown names as the rules package does
+func GameModeFor(rulesetName string) GameMode {
+	if mode, found := gameModes[rulesetName]; found {
+		return mode
+	}
+	return gameModes[rules.GameTypeStandard]
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestGameModes(t *testing.T) {
+	tests := []struct {
+		ruleset  string
+		mode     GameMode
+		tailFree int      // TurnsUntilFree of our tail
+		moves    []string // our considered moves
+	}{
+		{rules.GameTypeStandard, GameMode{Name: rules.GameTypeStandard}, 1, []string{"up", "right"}},
+		{rules.GameTypeSolo, GameMode{Name: rules.GameTypeSolo, Solo: true}, 1, []string{"up", "right"}},
+		{rules.GameTypeRoyale, GameMode{Name: rules.GameTypeRoyale, HazardsShrink: true}, 1, []string{"up", "right"}},
+		{rules.GameTypeConstrictor, GameMode{Name: rules.GameTypeConstrictor, SnakesAlwaysGrow: true}, NeverFree, []string{"up"}},
+		{rules.GameTypeWrapped, GameMode{Name: rules.GameTypeWrapped, Wrapped: true}, 1, []string{"up", "right", "left"}},
+		{rules.GameTypeWrappedConstrictor, GameMode{Name: rules.GameTypeWrappedConstrictor, Wrapped: true, SnakesAlwaysGrow: true}, NeverFree, []string{"up", "left"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ruleset, func(t *testing.T) {
+			if got := GameModeFor(tt.ruleset); got != tt.mode {
+				t.Fatalf("GameModeFor = %+v, want %+v", got, tt.mode)
+			}
+
+			// Our head is on the left edge with our tail just to its right
+			snakes := []client.Snake{testSnake("a", pt(0, 1), pt(0, 0), pt(1, 0), pt(1, 1))}
+			if !tt.mode.Solo {
+				snakes = append(snakes, testSnake("b", pt(5, 4), pt(5, 5), pt(5, 6)))
+			}
+			request := testRequest(tt.ruleset, 7, 7, snakes...)
+			request.Board.Hazards = []client.Coord{pt(6, 0)}
+			snapshot := NewGameSnapshot(request)
+
+			if snapshot.GameMode() != tt.mode {
+				t.Errorf("snapshot mode = %+v, want %+v", snapshot.GameMode(), tt.mode)
+			}
+			if got := snapshot.Board().Cells[1][1].TurnsUntilFree(); got != tt.tailFree {
+				t.Errorf("tail TurnsUntilFree = %d, want %d", got, tt.tailFree)
+			}
+			if got := snakeMovesToStrings(snapshot.You().ConsideredMoves()); !sameMoves(got, tt.moves) {
+				t.Errorf("considered moves = %v, want %v", got, tt.moves)
+			}
+
+			// Simulated turns keep the hazards, even where they shrink
+			moves := []rules.SnakeMove{{ID: "a", Move: "up"}}
+			if !tt.mode.Solo {
+				moves = append(moves, rules.SnakeMove{ID: "b", Move: "down"})
+			}
+			next, err := snapshot.ApplyMoves(moves)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []rules.Point{{X: 6, Y: 0}}; !slices.Equal(next.Hazards(), want) {
+				t.Errorf("hazards after a turn = %v, want %v", next.Hazards(), want)
+			}
+		})
+	}
+}
+
+func TestGameModeForUnknownRuleset(t *testing.T) {
+	if got := GameModeFor("not-a-mode"); got != GameModeFor(rules.GameTypeStandard) {
+		t.Errorf("GameModeFor = %+v, want standard", got)
+	}
+}
@@ -22,22 +22,34 @@ type GameSnapshot interface {
 	Teammates() []SnakeSnapshot
 	YourTeam() []SnakeSnapshot
 	Opponents() []SnakeSnapshot
+	Team(snakeID string) string
 	AllSnakes() []SnakeSnapshot
 	DeadSnakes() []SnakeSnapshot
 	Board() *Board
+	Bitboard() *Bitboard
+	Topology() Topology
+	GameMode() GameMode
+	ForecastHazards(turns int) HazardForecast
 	ApplyMoves(moves []rules.SnakeMove) (GameSnapshot, error)
+	FromPerspective(snakeID string) GameSnapshot
+	Hash() uint64
 }
 
 type gameSnapshotImpl struct {
-	gameID      string
-	ruleset     rules.Ruleset
-	boardState  *rules.BoardState // must not be nil
-	snakeStats  map[string]*snakeStatsImpl
-	yourID      string
-	allyIDs     []string
-	opponentIDs []string
-	board       *Board // lazy evaluated
-	boardOnce        sync.Once
+	gameID       string
+	ruleset      rules.Ruleset
+	boardState   *rules.BoardState // must not be nil
+	snakeStats   map[string]*snakeStatsImpl
+	yourID       string
+	allyIDs      []string
+	opponentIDs  []string
+	teams        map[string]string // snake ID -> team key
+	board        *Board            // lazy evaluated
+	boardOnce    sync.Once
+	bitboard     *Bitboard // lazy evaluated
+	bitboardOnce sync.Once
+	hash         uint64 // lazy evaluated
+	hashOnce     sync.Once
 }
 
 // GameSnapshot interface implementation
@@ -103,8 +115,8 @@ func (g *gameSnapshotImpl) getSnakeById(id string) SnakeSnapshot {
 	}
 
 	return &snakeSnapshotImpl{
-		stats: snakeStat,
-		snake: &snake,
+		stats:        snakeStat,
+		snake:        &snake,
 		gameSnapshot: g,
 	}
 }
@@ -142,6 +154,12 @@ func (g *gameSnapshotImpl) Opponents() []SnakeSnapshot {
 	})
 }
 
+// Team returns the key of the team the snake plays for; snakes with equal
+// keys are allies
+func (g *gameSnapshotImpl) Team(snakeID string) string {
+	return g.teams[snakeID]
+}
+
 func (g *gameSnapshotImpl) ApplyMoves(moves []rules.SnakeMove) (GameSnapshot, error) {
 	if len(moves) == 0 {
 		log.Fatalf("No moves provided: %+v", moves)
@@ -161,10 +179,19 @@ func (g *gameSnapshotImpl) ApplyMoves(moves []rules.SnakeMove) (GameSnapshot, er
 		log.Printf("Error executing moves: %v", err)
 		return nil, err
 	}
+	// The ruleset leaves advancing the turn to the game engine
+	nextBoardState.Turn = g.boardState.Turn + 1
 	return g.UpdateGameSnapshotBoardState(nextBoardState), nil
 }
 
+// NewGameSnapshot creates a snapshot of the request, treating snakes of the
+// same color as allies
 func NewGameSnapshot(request *client.SnakeRequest) GameSnapshot {
+	return NewGameSnapshotWithTeams(request, NewColorTeamResolver())
+}
+
+// NewGameSnapshotWithTeams creates a snapshot of the request, with allies decided by the resolver
+func NewGameSnapshotWithTeams(request *client.SnakeRequest, resolver TeamResolver) GameSnapshot {
 	if request == nil {
 		log.Println("Error: Request is nil")
 		return nil
@@ -174,10 +201,22 @@ func NewGameSnapshot(request *client.SnakeRequest) GameSnapshot {
 	rulesetName := request.Game.Ruleset.Name
 	// log.Println("Creating game snapshot for ruleset:", rulesetName)
 
-	ruleset := rules.NewRulesetBuilder().
+	// A lone snake in another mode is a practice game, which the engine plays
+	// until that snake dies
+	solo := GameModeFor(rulesetName).Solo || len(request.Board.Snakes) < 2
+
+	// Seed the simulated ruleset from the game so that its random stages, such
+	// as food spawning, give the same result each time a state is simulated
+	builder := rules.NewRulesetBuilder().
 		WithParams(ConvertRulesetSettingsToMap(request.Game.Ruleset.Settings)).
-		WithSolo(len(request.Board.Snakes) < 2).
-		NamedRuleset(rulesetName)
+		WithSeed(int64(idKey(request.Game.ID))).
+		WithSolo(solo)
+	var ruleset rules.Ruleset
+	if GameModeFor(rulesetName).HazardsShrink {
+		ruleset = builder.PipelineRuleset(rulesetName, rules.NewPipeline(simulatedShrinkStages(solo)...))
+	} else {
+		ruleset = builder.NamedRuleset(rulesetName)
+	}
 
 	if ruleset == nil {
 		panic("Failed to create ruleset for request: " + rulesetName)
@@ -193,15 +232,17 @@ func NewGameSnapshot(request *client.SnakeRequest) GameSnapshot {
 			turnLastShouted: turnLastShouted,
 		}
 	}
-	color := request.You.Customizations.Color
+	// A snake the resolver missed plays alone, rather than joining every
+	// other missed snake on the empty team
+	teams := lo.Assign(resolver.Teams(request))
+	for _, snake := range requestSnakes(request) {
+		if _, found := teams[snake.ID]; !found {
+			log.Printf("Warning: no team for snake %s, assuming it plays alone", snake.ID)
+			teams[snake.ID] = soloTeam(snake.ID)
+		}
+	}
 
-	allyIDs := lo.FilterMap(request.Board.Snakes, func(snake client.Snake, _ int) (string, bool) {
-		return snake.ID, snake.Customizations.Color == color
-	})
-
-	opponentIDs := lo.FilterMap(request.Board.Snakes, func(snake client.Snake, _ int) (string, bool) {
-		return snake.ID, snake.Customizations.Color != color
-	})
+	allyIDs, opponentIDs := splitTeams(boardState.Snakes, teams, request.You.ID)
 
 	return &gameSnapshotImpl{
 		gameID:      request.Game.ID,
@@ -211,6 +252,39 @@ func NewGameSnapshot(request *client.SnakeRequest) GameSnapshot {
 		yourID:      request.You.ID,
 		allyIDs:     allyIDs,
 		opponentIDs: opponentIDs,
+		teams:       teams,
+	}
+}
+
+// splitTeams partitions snake IDs into those on yourID's team and the rest
+func splitTeams(snakes []rules.Snake, teams map[string]string, yourID string) ([]string, []string) {
+	yourTeam := teams[yourID]
+	allyIDs := lo.FilterMap(snakes, func(snake rules.Snake, _ int) (string, bool) {
+		return snake.ID, teams[snake.ID] == yourTeam
+	})
+	opponentIDs := lo.FilterMap(snakes, func(snake rules.Snake, _ int) (string, bool) {
+		return snake.ID, teams[snake.ID] != yourTeam
+	})
+	return allyIDs, opponentIDs
+}
+
+// FromPerspective returns the same game state as seen by another snake, so
+// that heuristics score it for that snake's team.
+func (g *gameSnapshotImpl) FromPerspective(snakeID string) GameSnapshot {
+	if snakeID == g.yourID {
+		return g
+	}
+	allyIDs, opponentIDs := splitTeams(g.boardState.Snakes, g.teams, snakeID)
+	return &gameSnapshotImpl{
+		gameID:      g.gameID,
+		boardState:  g.boardState,
+		ruleset:     g.ruleset,
+		snakeStats:  g.snakeStats,
+		yourID:      snakeID,
+		allyIDs:     allyIDs,
+		opponentIDs: opponentIDs,
+		teams:       g.teams,
+		board:       nil, // Reset board cache
 	}
 }
 
@@ -226,6 +300,7 @@ func (g *gameSnapshotImpl) UpdateGameSnapshotBoardState(newBoardState *rules.Boa
 		yourID:      g.yourID,
 		allyIDs:     g.allyIDs,
 		opponentIDs: g.opponentIDs,
+		teams:       g.teams,
 		board:       nil, // Reset board cache
 	}
 }
@@ -235,4 +310,31 @@ func (g *gameSnapshotImpl) Board() *Board {
 		g.board = NewBoard(g)
 	})
 	return g.board
-}
\ No newline at end of file
+}
+
+// GameMode returns the rules of the game's mode
+func (g *gameSnapshotImpl) GameMode() GameMode {
+	return GameModeFor(g.ruleset.Name())
+}
+
+// Topology returns how cells connect under the game's ruleset
+func (g *gameSnapshotImpl) Topology() Topology {
+	return NewTopology(g.ruleset.Name(), g.boardState.Width, g.boardState.Height)
+}
+
+// Bitboard returns the packed form of Board(), or nil if the board is larger
+// than MaxBitboardSize
+func (g *gameSnapshotImpl) Bitboard() *Bitboard {
+	g.bitboardOnce.Do(func() {
+		g.bitboard = NewBitboard(g)
+	})
+	return g.bitboard
+}
+
+// Hash returns a Zobrist hash of the position as seen by You()
+func (g *gameSnapshotImpl) Hash() uint64 {
+	g.hashOnce.Do(func() {
+		g.hash = zobristHash(g.boardState, g.yourID)
+	})
+	return g.hash
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"math"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// HazardForecast predicts the hazard layout on a future turn
+type HazardForecast struct {
+	Turn int
+	// Shrinks is the number of times the hazard zone closes in before Turn,
+	// capped at the board's width plus height, by which point no cell is safe
+	Shrinks int
+	// Probabilities holds the chance that each cell, indexed [y][x], is hazardous on Turn
+	Probabilities [][]float64
+}
+
+// Risk returns the chance that p is hazardous on the forecast turn
+func (f HazardForecast) Risk(p rules.Point) float64 {
+	return f.Probabilities[p.Y][p.X]
+}
+
+// ForecastHazards predicts which cells will be hazardous the given number of
+// turns from now. Outside royale the hazards are assumed to stay put. In
+// royale the safe zone loses one row or column from a random side every
+// ShrinkEveryNTurns turns; the engine's seed is unknown to us, so each side is
+// taken to be equally likely.
+func (g *gameSnapshotImpl) ForecastHazards(turns int) HazardForecast {
+	forecast := HazardForecast{
+		Turn:          g.Turn() + turns,
+		Probabilities: make([][]float64, g.Height()),
+	}
+	for y := range forecast.Probabilities {
+		forecast.Probabilities[y] = make([]float64, g.Width())
+	}
+
+	shrinkEvery := g.ruleset.Settings().Int(rules.ParamShrinkEveryNTurns, 20)
+	if !g.GameMode().HazardsShrink || turns <= 0 || shrinkEvery < 1 {
+		for _, hazard := range g.Hazards() {
+			if hazard.X >= 0 && hazard.X < g.Width() && hazard.Y >= 0 && hazard.Y < g.Height() {
+				forecast.Probabilities[hazard.Y][hazard.X] = 1
+			}
+		}
+		return forecast
+	}
+	forecast.Shrinks = min(forecast.Turn/shrinkEvery-g.Turn()/shrinkEvery, g.Width()+g.Height())
+
+	// The current safe zone is the rectangle around every cell without a hazard
+	hazardous := make(map[rules.Point]bool, len(g.Hazards()))
+	for _, hazard := range g.Hazards() {
+		hazardous[hazard] = true
+	}
+	minX, maxX, minY, maxY := g.Width(), -1, g.Height(), -1
+	for y := 0; y < g.Height(); y++ {
+		for x := 0; x < g.Width(); x++ {
+			if !hazardous[rules.Point{X: x, Y: y}] {
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			}
+		}
+	}
+
+	// Sum the chance of each way the shrinks can split between the left,
+	// right, bottom and top sides into the cells that stay safe, using a 2D
+	// difference array over the resulting rectangles
+	n := forecast.Shrinks
+	safe := make([][]float64, g.Height()+1)
+	for y := range safe {
+		safe[y] = make([]float64, g.Width()+1)
+	}
+	logCombinations := func(left, right, bottom, top int) float64 {
+		lf := func(k int) float64 {
+			v, _ := math.Lgamma(float64(k + 1))
+			return v
+		}
+		return lf(n) - lf(left) - lf(right) - lf(bottom) - lf(top) - float64(n)*math.Log(4)
+	}
+	for left := 0; left <= n; left++ {
+		for right := 0; left+right <= n; right++ {
+			for bottom := 0; left+right+bottom <= n; bottom++ {
+				top := n - left - right - bottom
+				x0, x1, y0, y1 := minX+left, maxX-right, minY+bottom, maxY-top
+				if x0 > x1 || y0 > y1 {
+					continue
+				}
+				p := math.Exp(logCombinations(left, right, bottom, top))
+				safe[y0][x0] += p
+				safe[y0][x1+1] -= p
+				safe[y1+1][x0] -= p
+				safe[y1+1][x1+1] += p
+			}
+		}
+	}
+
+	for y := 0; y < g.Height(); y++ {
+		for x := 0; x < g.Width(); x++ {
+			if y > 0 {
+				safe[y][x] += safe[y-1][x]
+			}
+			if x > 0 {
+				safe[y][x] += safe[y][x-1]
+			}
+			if x > 0 && y > 0 {
+				safe[y][x] -= safe[y-1][x-1]
+			}
+			forecast.Probabilities[y][x] = math.Max(0, 1-safe[y][x])
+		}
+	}
+
+	return forecast
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"math"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+func TestForecastHazards(t *testing.T) {
+	// Turn 1 of a 7x7 royale game with no hazards yet, shrinking every 25 turns
+	snapshot := NewGameSnapshot(testRequest("royale", 7, 7,
+		testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)),
+		testSnake("b", pt(5, 5), pt(5, 6), pt(6, 6))))
+
+	tests := []struct {
+		name        string
+		turns       int
+		wantShrinks int
+		risks       map[rules.Point]float64
+	}{
+		{"before the first shrink", 10, 0, map[rules.Point]float64{{X: 0, Y: 3}: 0}},
+		{"after two shrinks", 50, 2, map[rules.Point]float64{
+			{X: 0, Y: 3}: 1 - 0.75*0.75, // unless neither shrink is from the left
+			{X: 0, Y: 0}: 1 - 0.5*0.5,   // unless neither is from the left or bottom
+			{X: 1, Y: 3}: 0.25 * 0.25,   // only if both are from the left
+			{X: 3, Y: 3}: 0,
+		}},
+		{"capped", 1_000_000, 7 + 7, map[rules.Point]float64{{X: 3, Y: 3}: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast := snapshot.ForecastHazards(tt.turns)
+			if forecast.Shrinks != tt.wantShrinks {
+				t.Errorf("shrinks = %d, want %d", forecast.Shrinks, tt.wantShrinks)
+			}
+			for p, want := range tt.risks {
+				if got := forecast.Risk(p); math.Abs(got-want) > 1e-9 {
+					t.Errorf("risk at %v = %v, want %v", p, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestForecastHazardsOutsideRoyale(t *testing.T) {
+	request := testRequest("standard", 7, 7,
+		testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)),
+		testSnake("b", pt(5, 5), pt(5, 6), pt(6, 6)))
+	request.Board.Hazards = append(request.Board.Hazards, pt(0, 0))
+	forecast := NewGameSnapshot(request).ForecastHazards(100)
+
+	if forecast.Risk(rules.Point{X: 0, Y: 0}) != 1 || forecast.Risk(rules.Point{X: 0, Y: 1}) != 0 {
+		t.Errorf("forecast = %v, want the current hazards", forecast.Probabilities)
+	}
+}
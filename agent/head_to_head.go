@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/samber/lo"
+)
+
+// contestedMoveWeight is how much less likely HeadToHeadDeprioritize makes a
+// move into a cell an equal or longer enemy head could also enter
+const contestedMoveWeight = 0.25
+
+// HeadThreat is a snake whose head could move into a cell next turn
+type HeadThreat struct {
+	SnakeID string
+	Length  int
+}
+
+// HeadThreatsTo maps every passable cell that the head of a snake outside
+// team could move into next turn to the snakes that could move there
+func HeadThreatsTo(g GameSnapshot, team string) map[rules.Point][]HeadThreat {
+	board := g.Board()
+	threats := make(map[rules.Point][]HeadThreat)
+	for _, snake := range g.AliveSnakes() {
+		if g.Team(snake.ID()) == team {
+			continue
+		}
+		for _, cell := range getPassableNeighbours(board, snake.Head()) {
+			target := cell.Coordinates()
+			threats[target] = append(threats[target], HeadThreat{SnakeID: snake.ID(), Length: snake.Length()})
+		}
+	}
+	return threats
+}
+
+// isContested reports whether a snake at least length long threatens target
+func isContested(threats map[rules.Point][]HeadThreat, target rules.Point, length int) bool {
+	return lo.SomeBy(threats[target], func(threat HeadThreat) bool {
+		return threat.Length >= length
+	})
+}
+
+// deprioritizeContestedMoves scales the probabilities of our moves into
+// contested cells by contestedMoveWeight under HeadToHeadDeprioritize. It is
+// applied once, to the final move probabilities in ChooseMoveWithTrace; the
+// searches score every considered move unweighted.
+func (sa *SnakeAgent) deprioritizeContestedMoves(snapshot GameSnapshot, moves []string, probs []float64) []float64 {
+	if sa.HeadToHead != HeadToHeadDeprioritize {
+		return probs
+	}
+	you := snapshot.You()
+	threats := HeadThreatsTo(snapshot, snapshot.Team(you.ID()))
+	return normalizedWeights(lo.Map(probs, func(p float64, i int) float64 {
+		return p * lo.Ternary(isContested(threats, you.MoveTarget(moves[i]), you.Length()), contestedMoveWeight, 1.0)
+	}))
+}
+
+// responseWeights returns the opponent model's weights for the next states.
+// Under HeadToHeadDeprioritize each state is also scaled by
+// contestedMoveWeight for every other snake that moved into a contested cell.
+func (sa *SnakeAgent) responseWeights(snapshot GameSnapshot, nextStates []GameSnapshot, values []float64) []float64 {
+	weights := sa.OpponentModel.Weights(snapshot, nextStates, values)
+	if sa.HeadToHead != HeadToHeadDeprioritize {
+		return weights
+	}
+
+	yourID := snapshot.You().ID()
+	others := lo.Filter(snapshot.AliveSnakes(), func(snake SnakeSnapshot, _ int) bool {
+		return snake.ID() != yourID
+	})
+	threats := lo.Map(others, func(snake SnakeSnapshot, _ int) map[rules.Point][]HeadThreat {
+		return HeadThreatsTo(snapshot, snapshot.Team(snake.ID()))
+	})
+	return lo.Map(weights, func(weight float64, i int) float64 {
+		heads := lo.SliceToMap(nextStates[i].AllSnakes(), func(snake SnakeSnapshot) (string, rules.Point) {
+			return snake.ID(), snake.Head()
+		})
+		for j, snake := range others {
+			if isContested(threats[j], heads[snake.ID()], snake.Length()) {
+				weight *= contestedMoveWeight
+			}
+		}
+		return weight
+	})
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/samber/lo"
+)
+
+// headToHeadRequest has b's head two cells right of ours, so our move right
+// is contested unless b is shorter or an ally
+func headToHeadRequest(bLength int, bColor string) *client.SnakeRequest {
+	b := testSnake("b", pt(5, 3), pt(6, 3), pt(6, 2), pt(6, 1))
+	b.Body, b.Length = b.Body[:bLength], bLength
+	b.Customizations.Color = bColor
+	return testRequest("standard", 7, 7, testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)), b)
+}
+
+func TestConsideredMovesExcludesContested(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *client.SnakeRequest
+		policy  HeadToHeadPolicy
+		want    []string
+	}{
+		{"ignore", headToHeadRequest(3, "b"), HeadToHeadIgnore, []string{"up", "left", "right"}},
+		{"deprioritize keeps every move", headToHeadRequest(3, "b"), HeadToHeadDeprioritize, []string{"up", "left", "right"}},
+		{"exclude", headToHeadRequest(3, "b"), HeadToHeadExclude, []string{"up", "left"}},
+		{"longer enemy", headToHeadRequest(4, "b"), HeadToHeadExclude, []string{"up", "left"}},
+		{"shorter enemy", headToHeadRequest(2, "b"), HeadToHeadExclude, []string{"up", "left", "right"}},
+		{"ally", headToHeadRequest(3, "a"), HeadToHeadExclude, []string{"up", "left", "right"}},
+		{"every move contested", testRequest("standard", 7, 7,
+			testSnake("a", pt(0, 1), pt(0, 0), pt(1, 0)),
+			testSnake("b", pt(1, 2), pt(2, 2), pt(3, 2))), HeadToHeadExclude, []string{"up", "right"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moves := NewGameSnapshot(tt.request).You().ConsideredMoves(AvoidHeadToHead(tt.policy))
+			if got := snakeMovesToStrings(moves); !sameMoves(got, tt.want) {
+				t.Errorf("moves = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeprioritizeWeightsContestedMoves(t *testing.T) {
+	// A flat portfolio leaves the argmax selector splitting every move evenly
+	portfolio := NewPortfolio(NewHeuristic(1, "flat", func(GameSnapshot) float64 { return 0 }))
+	tests := []struct {
+		name string
+		opts []SnakeAgentOption
+	}{
+		{"one-ply", nil},
+		{"expectimax", []SnakeAgentOption{WithExpectimax(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := NewMemoryTraceSink()
+			opts := append([]SnakeAgentOption{
+				WithHeadToHeadPolicy(HeadToHeadDeprioritize), WithMoveSelector(NewArgmaxSelector()), WithTraceSink(sink),
+			}, tt.opts...)
+			sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{}, opts...)
+			sa.ChooseMoveWithContext(context.Background(), sa.NewGameSnapshot(headToHeadRequest(3, "b")))
+
+			probs := sink.Traces()[0].Probabilities
+			total := 2 + contestedMoveWeight
+			want := map[string]float64{"left": 1 / total, "up": 1 / total, "right": contestedMoveWeight / total}
+			for move, p := range want {
+				if math.Abs(probs[move]-p) > 1e-9 {
+					t.Errorf("probabilities = %v, want %v", probs, want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestResponseWeightsDeprioritizeContestedOpponents(t *testing.T) {
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+	sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{}, WithHeadToHeadPolicy(HeadToHeadDeprioritize))
+	snapshot := sa.NewGameSnapshot(headToHeadRequest(3, "b"))
+
+	// We move up; b's move left is into (4, 3), next to our head
+	states := sa.generateNextStates(context.Background(), snapshot, "up", 0)
+	weights := sa.responseWeights(snapshot, states, make([]float64, len(states)))
+	for i, state := range states {
+		b, _ := lo.Find(state.AllSnakes(), func(snake SnakeSnapshot) bool { return snake.ID() == "b" })
+		want := lo.Ternary(b.Head() == rules.Point{X: 4, Y: 3}, contestedMoveWeight, 1.0)
+		if weights[i] != want {
+			t.Errorf("b at %v: weight = %v, want %v", b.Head(), weights[i], want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// pt is shorthand for a board coordinate
+func pt(x, y int) client.Coord {
+	return client.Coord{X: x, Y: y}
+}
+
+// testSnake returns a snake at full health whose color is its ID, so every
+// test snake is on its own team unless the test recolors it
+func testSnake(id string, body ...client.Coord) client.Snake {
+	return client.Snake{
+		ID:             id,
+		Name:           id,
+		Health:         100,
+		Body:           body,
+		Head:           body[0],
+		Length:         len(body),
+		Customizations: client.Customizations{Color: id},
+	}
+}
+
+// testRequest returns a request seen by the first snake. Food never spawns,
+// so simulated turns only change what the moves change.
+func testRequest(ruleset string, width, height int, snakes ...client.Snake) *client.SnakeRequest {
+	return &client.SnakeRequest{
+		Game: client.Game{
+			ID:      "test-game",
+			Timeout: 500,
+			Ruleset: client.Ruleset{
+				Name: ruleset,
+				Settings: client.RulesetSettings{
+					HazardDamagePerTurn: 14,
+					RoyaleSettings:      client.RoyaleSettings{ShrinkEveryNTurns: 25},
+				},
+			},
+		},
+		Turn: 1,
+		Board: client.Board{
+			Width:  width,
+			Height: height,
+			Snakes: snakes,
+		},
+		You: snakes[0],
+	}
+}
+
+// lengthHeuristic scores a state by the total length of the alive snakes on
+// the perspective snake's team
+func lengthHeuristic(snapshot GameSnapshot) float64 {
+	total := 0
+	for _, snake := range snapshot.YourTeam() {
+		if snake.Alive() {
+			total += snake.Length()
+		}
+	}
+	return float64(total)
+}
+
+// foodTrapRequest returns a solo game where food lures our snake left into
+// the corner, where it is boxed in by its own body and dies the next turn.
+// Moving right is the only move that survives two turns.
+func foodTrapRequest() *client.SnakeRequest {
+	request := testRequest("solo", 7, 7,
+		testSnake("a", pt(1, 0), pt(1, 1), pt(0, 1), pt(0, 2), pt(1, 2), pt(2, 2), pt(3, 2)))
+	request.Board.Food = []client.Coord{pt(0, 0)}
+	return request
+}
@@ -0,0 +1,303 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/Battle-Bunker/cyphid-snake/lib"
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/samber/lo"
+)
+
+// mctsNode is a game state in the search tree. Its edges are our candidate
+// moves; each edge branches again on the opponents' joint move, which is
+// sampled from the opponent model rather than enumerated.
+type mctsNode struct {
+	snapshot GameSnapshot
+	edges    []*mctsEdge
+	visits   int
+	terminal bool
+}
+
+// mctsEdge holds the statistics for one of our moves out of a node.
+type mctsEdge struct {
+	move         string
+	prior        float64
+	initialValue float64 // expected portfolio value of the move under the opponent model
+	jointMoves   [][]rules.SnakeMove
+	jointWeights []float64 // opponent model likelihood of each joint move, summing to one
+	visits       int
+	valueSum     float64
+	children     map[string]*mctsNode // keyed by the joint move of the other snakes
+}
+
+func (e *mctsEdge) meanValue() float64 {
+	if e.visits == 0 {
+		return e.initialValue
+	}
+	return e.valueSum / float64(e.visits)
+}
+
+// defaultMCTSIterations caps an uncapped search that has no deadline
+const defaultMCTSIterations = 500
+
+type mctsSearch struct {
+	agent *SnakeAgent
+	root  *mctsNode
+	rng   *rand.Rand
+}
+
+func newMCTSSearch(sa *SnakeAgent, snapshot GameSnapshot, rng *rand.Rand) *mctsSearch {
+	return &mctsSearch{
+		agent: sa,
+		root:  &mctsNode{snapshot: snapshot},
+		rng:   rng,
+	}
+}
+
+// run performs selection/expansion/rollout/backprop iterations until the
+// iteration cap is reached or the context is done, and returns the number of
+// iterations completed. A cap of 0 runs until the context is done.
+func (m *mctsSearch) run(ctx context.Context, iterations int) int {
+	if _, hasDeadline := ctx.Deadline(); iterations <= 0 && !hasDeadline {
+		iterations = defaultMCTSIterations
+	}
+	completed := 0
+	for ; iterations <= 0 || completed < iterations; completed++ {
+		if ctx.Err() != nil {
+			break
+		}
+		m.iterate(ctx)
+	}
+	return completed
+}
+
+func (m *mctsSearch) iterate(ctx context.Context) {
+	node := m.root
+	nodes := []*mctsNode{node}
+	edges := []*mctsEdge{}
+	var value float64
+
+	for {
+		if node.terminal || !node.snapshot.You().Alive() {
+			node.terminal = true
+			value = m.agent.evaluate(node.snapshot)
+			break
+		}
+		if node.edges == nil && !m.expand(ctx, node) {
+			return // out of time, with nothing to back up
+		}
+
+		// Selection
+		edge := m.selectEdge(node)
+		edges = append(edges, edge)
+
+		moves := m.sampleJointMove(edge)
+		if moves == nil {
+			value = m.agent.evaluate(node.snapshot)
+			break
+		}
+		key := jointMoveKey(moves, node.snapshot.You().ID())
+		child, found := edge.children[key]
+		if found {
+			node = child
+			nodes = append(nodes, node)
+			continue
+		}
+
+		// Expansion
+		nextState, err := node.snapshot.ApplyMoves(moves)
+		if err != nil {
+			log.Printf("MCTS: error applying moves: %v", err)
+			value = m.agent.evaluate(node.snapshot)
+			break
+		}
+		child = &mctsNode{snapshot: nextState}
+		edge.children[key] = child
+		nodes = append(nodes, child)
+
+		// Rollout
+		value = m.rollout(nextState)
+		break
+	}
+
+	// Backpropagation
+	for _, n := range nodes {
+		n.visits++
+	}
+	for _, e := range edges {
+		e.visits++
+		e.valueSum += value
+	}
+}
+
+// expand creates an edge for each of our considered moves. Every joint move
+// of the other snakes is weighted by the opponent model, and the expected
+// portfolio value of the resulting states sets the edge's prior. It returns
+// false, leaving the node unexpanded, if the context is done first.
+func (m *mctsSearch) expand(ctx context.Context, node *mctsNode) bool {
+	moves := snakeMovesToStrings(m.agent.consideredMoves(node.snapshot.You()))
+	slices.Sort(moves)
+
+	edges := make([]*mctsEdge, 0, len(moves))
+	for _, move := range moves {
+		edge, complete := m.newEdge(ctx, node.snapshot, move)
+		if !complete {
+			return false
+		}
+		edges = append(edges, edge)
+	}
+	node.edges = edges
+	values := lo.Map(node.edges, func(e *mctsEdge, _ int) float64 { return e.initialValue })
+	priors := lib.SoftmaxWithTemp(values, m.agent.Temperature)
+	for i, edge := range node.edges {
+		edge.prior = priors[i]
+	}
+	return true
+}
+
+// newEdge enumerates the joint moves in which we play move and weights them
+// with the opponent model, or returns false if the context is done first
+func (m *mctsSearch) newEdge(ctx context.Context, snapshot GameSnapshot, move string) (*mctsEdge, bool) {
+	edge := &mctsEdge{move: move, children: make(map[string]*mctsNode)}
+	var states []GameSnapshot
+	for _, jointMove := range m.agent.nextMoveCombinations(snapshot, move, m.agent.InteractionRadius) {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		nextState, err := snapshot.ApplyMoves(jointMove)
+		if err != nil {
+			continue
+		}
+		edge.jointMoves = append(edge.jointMoves, jointMove)
+		states = append(states, nextState)
+	}
+	if len(states) == 0 {
+		edge.initialValue = m.agent.evaluate(snapshot)
+		return edge, true
+	}
+
+	values := make([]float64, len(states))
+	for i, state := range states {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		values[i] = m.agent.evaluate(state)
+	}
+	edge.jointWeights = normalizedWeights(m.agent.responseWeights(snapshot, states, values))
+	edge.initialValue = lib.WeightedMean(values, edge.jointWeights)
+	return edge, true
+}
+
+// selectEdge picks the edge maximizing the PUCT score, with mean values
+// min-max normalized across siblings so the exploration constant is
+// independent of the portfolio's scale.
+func (m *mctsSearch) selectEdge(node *mctsNode) *mctsEdge {
+	means := lo.Map(node.edges, func(e *mctsEdge, _ int) float64 { return e.meanValue() })
+	minMean, maxMean := lo.Min(means), lo.Max(means)
+	spread := maxMean - minMean
+
+	sqrtVisits := math.Sqrt(float64(node.visits + 1))
+	return lo.MaxBy(node.edges, func(a, b *mctsEdge) bool {
+		return m.puct(a, minMean, spread, sqrtVisits) > m.puct(b, minMean, spread, sqrtVisits)
+	})
+}
+
+func (m *mctsSearch) puct(e *mctsEdge, minMean, spread, sqrtParentVisits float64) float64 {
+	q := 0.5
+	if spread > 0 {
+		q = (e.meanValue() - minMean) / spread
+	}
+	return q + m.agent.ExplorationConstant*e.prior*sqrtParentVisits/float64(1+e.visits)
+}
+
+// sampleJointMove samples a joint move for the edge's move from the opponent
+// model's weights.
+func (m *mctsSearch) sampleJointMove(edge *mctsEdge) []rules.SnakeMove {
+	if len(edge.jointMoves) == 0 {
+		return nil
+	}
+	return edge.jointMoves[lib.SampleFromWeightsWithRand(edge.jointWeights, m.rng)]
+}
+
+// rollout plays random considered moves for every snake for up to
+// RolloutDepth turns and evaluates the resulting state with the portfolio.
+// Rollouts stay uniformly random, as weighting every ply with the opponent
+// model would cost a full evaluation of each joint move.
+func (m *mctsSearch) rollout(snapshot GameSnapshot) float64 {
+	for depth := 0; depth < m.agent.RolloutDepth && snapshot.You().Alive(); depth++ {
+		moves := lo.Map(snapshot.AliveSnakes(), func(snake SnakeSnapshot, _ int) rules.SnakeMove {
+			return m.randomConsideredMove(snake)
+		})
+		nextState, err := snapshot.ApplyMoves(moves)
+		if err != nil {
+			break
+		}
+		snapshot = nextState
+	}
+	return m.agent.evaluate(snapshot)
+}
+
+// rootStats returns the visit count and mean value of each root move, aligned with moves.
+func (m *mctsSearch) rootStats(moves []string) ([]int, []float64) {
+	visits := make([]int, len(moves))
+	values := make([]float64, len(moves))
+	for _, edge := range m.root.edges {
+		if i := slices.Index(moves, edge.move); i >= 0 {
+			visits[i] = edge.visits
+			values[i] = edge.meanValue()
+		}
+	}
+	return visits, values
+}
+
+func (m *mctsSearch) randomConsideredMove(snake SnakeSnapshot) rules.SnakeMove {
+	moves := m.agent.consideredMoves(snake)
+	return moves[m.rng.Intn(len(moves))]
+}
+
+// jointMoveKey identifies the moves of every snake other than yourID.
+func jointMoveKey(moves []rules.SnakeMove, yourID string) string {
+	parts := lo.FilterMap(moves, func(m rules.SnakeMove, _ int) (string, bool) {
+		return m.ID + ":" + m.Move, m.ID != yourID
+	})
+	slices.Sort(parts)
+	return strings.Join(parts, ",")
+}
+
+// mctsMoveScores runs MCTS from snapshot and returns each root move's share
+// of the root visits, aligned with consideredMoveStrs. Visits rather than
+// mean values are the search's recommendation, as a move is only visited
+// often when its value holds up under exploration.
+func (sa *SnakeAgent) mctsMoveScores(ctx context.Context, snapshot GameSnapshot, consideredMoveStrs []string, rng *rand.Rand, trace *DecisionTrace) []float64 {
+	search := newMCTSSearch(sa, snapshot, rng)
+	if sa.ReuseSearchTrees {
+		if root := sa.searchTrees.take(snapshot); root != nil {
+			search.root = root
+			trace.ReusedVisits = root.visits
+		}
+	}
+	trace.MCTSIterations = search.run(ctx, sa.MCTSIterations)
+	if sa.ReuseSearchTrees {
+		sa.searchTrees.store(snapshot, search.root)
+	}
+
+	visits, values := search.rootStats(consideredMoveStrs)
+
+	trace.RootVisits = lo.SliceToMap(lo.Range(len(consideredMoveStrs)), func(i int) (string, int) {
+		return consideredMoveStrs[i], visits[i]
+	})
+	trace.RootValues = finiteValues(consideredMoveStrs, values)
+
+	totalVisits := lo.Sum(visits)
+	return lo.Map(visits, func(v int, _ int) float64 {
+		if totalVisits == 0 {
+			return 1.0 / float64(len(visits))
+		}
+		return float64(v) / float64(totalVisits)
+	})
+}
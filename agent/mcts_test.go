@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestMCTSAvoidsFoodTrap(t *testing.T) {
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+	tests := []struct {
+		name string
+		opts []SnakeAgentOption
+		want string
+	}{
+		{"one-ply takes the food", nil, "left"},
+		{"mcts", []SnakeAgentOption{WithMCTS(200), WithSeed(1)}, "right"},
+		{"mcts without rollouts", []SnakeAgentOption{WithMCTS(200), WithRolloutDepth(0), WithSeed(1)}, "right"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{},
+				append([]SnakeAgentOption{WithMoveSelector(NewArgmaxSelector())}, tt.opts...)...)
+			response, trace := sa.ChooseMoveWithTrace(context.Background(), sa.NewGameSnapshot(foodTrapRequest()))
+			if response.Move != tt.want {
+				t.Errorf("move = %s, want %s (scores %v)", response.Move, tt.want, trace.Scores)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Battle-Bunker/cyphid-snake/lib"
+	"github.com/samber/lo"
+)
+
+// MoveSelector turns the scores of the considered moves into the probability
+// of playing each move.
+type MoveSelector interface {
+	Name() string
+	Probabilities(scores []float64, turn int) []float64
+}
+
+// TemperatureSchedule gives the softmax temperature to use on a turn
+type TemperatureSchedule func(turn int) float64
+
+// ConstantTemperature uses the same temperature on every turn
+func ConstantTemperature(temp float64) TemperatureSchedule {
+	return func(int) float64 {
+		return temp
+	}
+}
+
+// AnnealedTemperature decays linearly from start to end over the given number
+// of turns and stays at end afterwards
+func AnnealedTemperature(start, end float64, turns int) TemperatureSchedule {
+	return func(turn int) float64 {
+		if turns <= 0 || turn >= turns {
+			return end
+		}
+		return start + (end-start)*float64(turn)/float64(turns)
+	}
+}
+
+// ArgmaxSelector always plays the best move, splitting ties evenly
+type ArgmaxSelector struct{}
+
+func NewArgmaxSelector() MoveSelector {
+	return ArgmaxSelector{}
+}
+
+func (ArgmaxSelector) Name() string {
+	return "argmax"
+}
+
+func (ArgmaxSelector) Probabilities(scores []float64, _ int) []float64 {
+	return withinOfBest(scores, 0)
+}
+
+// SoftmaxSelector samples moves from a softmax of their scores. A
+// temperature of zero or less, such as the end of a schedule annealed to
+// greedy play, plays the best move as ArgmaxSelector does.
+type SoftmaxSelector struct {
+	Schedule TemperatureSchedule
+}
+
+func NewSoftmaxSelector(schedule TemperatureSchedule) MoveSelector {
+	return SoftmaxSelector{Schedule: schedule}
+}
+
+func (s SoftmaxSelector) Name() string {
+	return "softmax"
+}
+
+func (s SoftmaxSelector) Probabilities(scores []float64, turn int) []float64 {
+	temp := s.Schedule(turn)
+	if temp <= 0 {
+		return withinOfBest(scores, 0)
+	}
+	return lib.SoftmaxWithTemp(scores, temp)
+}
+
+// ProportionalSelector plays each move with probability proportional to its
+// score, treating negative scores as zero. It suits scores that are already
+// a distribution, such as the share of MCTS root visits.
+type ProportionalSelector struct{}
+
+func NewProportionalSelector() MoveSelector {
+	return ProportionalSelector{}
+}
+
+func (ProportionalSelector) Name() string {
+	return "proportional"
+}
+
+func (ProportionalSelector) Probabilities(scores []float64, _ int) []float64 {
+	return normalizedWeights(lo.Map(scores, func(score float64, _ int) float64 {
+		return max(score, 0)
+	}))
+}
+
+// EpsilonGreedySelector plays the best move, except with probability Epsilon
+// plays a uniformly random move
+type EpsilonGreedySelector struct {
+	Epsilon float64
+}
+
+func NewEpsilonGreedySelector(epsilon float64) MoveSelector {
+	return EpsilonGreedySelector{Epsilon: epsilon}
+}
+
+func (s EpsilonGreedySelector) Name() string {
+	return fmt.Sprintf("epsilon-greedy(%g)", s.Epsilon)
+}
+
+func (s EpsilonGreedySelector) Probabilities(scores []float64, _ int) []float64 {
+	explore := s.Epsilon / float64(len(scores))
+	return lo.Map(withinOfBest(scores, 0), func(p float64, _ int) float64 {
+		return (1-s.Epsilon)*p + explore
+	})
+}
+
+// WithinOfBestSelector samples uniformly among the moves scoring within
+// Margin of the best move
+type WithinOfBestSelector struct {
+	Margin float64
+}
+
+func NewWithinOfBestSelector(margin float64) MoveSelector {
+	return WithinOfBestSelector{Margin: margin}
+}
+
+func (s WithinOfBestSelector) Name() string {
+	return fmt.Sprintf("within-of-best(%g)", s.Margin)
+}
+
+func (s WithinOfBestSelector) Probabilities(scores []float64, _ int) []float64 {
+	return withinOfBest(scores, s.Margin)
+}
+
+// withinOfBest spreads probability evenly over the moves scoring at least best-margin
+func withinOfBest(scores []float64, margin float64) []float64 {
+	best := lo.Max(scores)
+	candidates := lo.Map(scores, func(score float64, _ int) bool {
+		return score >= best-margin || math.IsInf(best, -1)
+	})
+	count := float64(lo.Count(candidates, true))
+	return lo.Map(candidates, func(candidate bool, _ int) float64 {
+		return lo.Ternary(candidate, 1/count, 0.0)
+	})
+}
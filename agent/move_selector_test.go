@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestMoveSelectorProbabilities(t *testing.T) {
+	inf := math.Inf(-1)
+	tests := []struct {
+		name     string
+		selector MoveSelector
+		scores   []float64
+		want     []float64
+	}{
+		{"argmax", NewArgmaxSelector(), []float64{1, 3, 2}, []float64{0, 1, 0}},
+		{"argmax splits ties", NewArgmaxSelector(), []float64{3, 3, inf}, []float64{0.5, 0.5, 0}},
+		{"softmax", NewSoftmaxSelector(ConstantTemperature(1)), []float64{0, math.Log(3)}, []float64{0.25, 0.75}},
+		{"softmax at zero temperature is argmax", NewSoftmaxSelector(ConstantTemperature(0)), []float64{1, 3, 2}, []float64{0, 1, 0}},
+		{"softmax at negative temperature is argmax", NewSoftmaxSelector(ConstantTemperature(-1)), []float64{3, 3, inf}, []float64{0.5, 0.5, 0}},
+		{"epsilon-greedy", NewEpsilonGreedySelector(0.3), []float64{1, 2, 0}, []float64{0.1, 0.8, 0.1}},
+		{"within-of-best", NewWithinOfBestSelector(1), []float64{5, 4.5, 3}, []float64{0.5, 0.5, 0}},
+		{"proportional", NewProportionalSelector(), []float64{0.2, 0.6, 0.2}, []float64{0.2, 0.6, 0.2}},
+		{"proportional ignores negatives", NewProportionalSelector(), []float64{-1, 3, 1}, []float64{0, 0.75, 0.25}},
+		{"proportional without mass", NewProportionalSelector(), []float64{0, 0}, []float64{0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.selector.Probabilities(tt.scores, 0)
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Fatalf("probabilities = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnnealedTemperature(t *testing.T) {
+	schedule := AnnealedTemperature(10, 2, 4)
+	for turn, want := range map[int]float64{0: 10, 2: 6, 4: 2, 100: 2} {
+		if got := schedule(turn); got != want {
+			t.Errorf("turn %d: temperature = %v, want %v", turn, got, want)
+		}
+	}
+
+	// Annealing to zero ends in greedy play rather than NaN probabilities
+	greedy := NewSoftmaxSelector(AnnealedTemperature(5, 0, 4))
+	for _, turn := range []int{4, 100} {
+		if got := greedy.Probabilities([]float64{1, 3, 2}, turn); got[0] != 0 || got[1] != 1 || got[2] != 0 {
+			t.Errorf("turn %d: probabilities = %v, want [0 1 0]", turn, got)
+		}
+	}
+}
+
+func TestMCTSScoresAreVisitShares(t *testing.T) {
+	snapshot := NewGameSnapshot(testRequest("standard", 7, 7,
+		testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)),
+		testSnake("b", pt(0, 6), pt(1, 6), pt(2, 6))))
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+	sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{}, WithMCTS(100))
+	moves := []string{"left", "right", "up"}
+
+	var trace DecisionTrace
+	scores := sa.mctsMoveScores(context.Background(), snapshot, moves, rand.New(rand.NewSource(1)), &trace)
+
+	if name := sa.moveSelector().Name(); name != "proportional" {
+		t.Errorf("default MCTS selector = %s, want proportional", name)
+	}
+	total := 0
+	for _, visits := range trace.RootVisits {
+		total += visits
+	}
+	for i, move := range moves {
+		if want := float64(trace.RootVisits[move]) / float64(total); math.Abs(scores[i]-want) > 1e-9 {
+			t.Errorf("%s: score = %v, want visit share %v", move, scores[i], want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"math"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/samber/lo"
+)
+
+// OpponentModel predicts how the other snakes will respond to one of our moves.
+// Given the next states reachable by that move (one per combination of the
+// other snakes' moves) and our portfolio value of each, it returns the
+// relative likelihood of each state.
+type OpponentModel interface {
+	Name() string
+	Weights(snapshot GameSnapshot, nextStates []GameSnapshot, values []float64) []float64
+}
+
+// UniformOpponentModel treats every combination of opponent moves as equally likely
+type UniformOpponentModel struct{}
+
+func NewUniformOpponentModel() OpponentModel {
+	return UniformOpponentModel{}
+}
+
+func (UniformOpponentModel) Name() string {
+	return "uniform"
+}
+
+func (UniformOpponentModel) Weights(_ GameSnapshot, nextStates []GameSnapshot, _ []float64) []float64 {
+	return uniformWeights(len(nextStates))
+}
+
+// ParanoidOpponentModel assumes the opponents pick the combination that is worst for us
+type ParanoidOpponentModel struct{}
+
+func NewParanoidOpponentModel() OpponentModel {
+	return ParanoidOpponentModel{}
+}
+
+func (ParanoidOpponentModel) Name() string {
+	return "paranoid"
+}
+
+func (ParanoidOpponentModel) Weights(_ GameSnapshot, _ []GameSnapshot, values []float64) []float64 {
+	worst := lo.Min(values)
+	return lo.Map(values, func(v float64, _ int) float64 {
+		return lo.Ternary(v == worst, 1.0, 0.0)
+	})
+}
+
+// SoftmaxOpponentModel assumes the opponents favour states they rate highly,
+// weighting each state by a softmax over the sum of every opposing team's
+// portfolio evaluation of it. Our own team is left out, and each opposing
+// team is evaluated once, from the perspective of one of its snakes, so
+// teammates are not counted twice. A Temperature of zero or less keeps only
+// the highest rated states.
+type SoftmaxOpponentModel struct {
+	Portfolio   HeuristicPortfolio
+	Temperature float64
+}
+
+func NewSoftmaxOpponentModel(portfolio HeuristicPortfolio, temperature float64) OpponentModel {
+	return SoftmaxOpponentModel{Portfolio: portfolio, Temperature: temperature}
+}
+
+func (m SoftmaxOpponentModel) Name() string {
+	return "softmax"
+}
+
+func (m SoftmaxOpponentModel) Weights(snapshot GameSnapshot, nextStates []GameSnapshot, _ []float64) []float64 {
+	// One representative snake per team; its evaluation is the team's value
+	opponentIDs := lo.Map(snapshot.Opponents(), func(snake SnakeSnapshot, _ int) string { return snake.ID() })
+	teamIDs := lo.UniqBy(opponentIDs, snapshot.Team)
+	opponentValues := lo.Map(nextStates, func(state GameSnapshot, _ int) float64 {
+		return lo.SumBy(teamIDs, func(id string) float64 {
+			return m.Portfolio.Evaluate(state.FromPerspective(id))
+		})
+	})
+	if len(opponentValues) == 0 {
+		return opponentValues
+	}
+	maxValue := lo.Max(opponentValues)
+	if m.Temperature <= 0 {
+		return lo.Map(opponentValues, func(v float64, _ int) float64 {
+			return lo.Ternary(v == maxValue, 1.0, 0.0)
+		})
+	}
+	return lo.Map(opponentValues, func(v float64, _ int) float64 {
+		return math.Exp((v - maxValue) / m.Temperature)
+	})
+}
+
+// GreedyFoodOpponentModel assumes every other snake moves to reduce its
+// Manhattan distance to the nearest food.
+type GreedyFoodOpponentModel struct{}
+
+func NewGreedyFoodOpponentModel() OpponentModel {
+	return GreedyFoodOpponentModel{}
+}
+
+func (GreedyFoodOpponentModel) Name() string {
+	return "greedy-food"
+}
+
+func (GreedyFoodOpponentModel) Weights(snapshot GameSnapshot, nextStates []GameSnapshot, _ []float64) []float64 {
+	food := snapshot.Food()
+	if len(food) == 0 {
+		return uniformWeights(len(nextStates))
+	}
+
+	// distances[i][id] is the food distance of snake id's new head in state i
+	otherIDs := otherAliveSnakeIDs(snapshot)
+	distances := lo.Map(nextStates, func(state GameSnapshot, _ int) map[string]int {
+		return lo.SliceToMap(state.AllSnakes(), func(snake SnakeSnapshot) (string, int) {
+			return snake.ID(), nearestFoodDistance(state.Topology(), snake.Head(), food)
+		})
+	})
+	bestDistances := lo.SliceToMap(otherIDs, func(id string) (string, int) {
+		return id, lo.Min(lo.Map(distances, func(d map[string]int, _ int) int { return d[id] }))
+	})
+
+	weights := lo.Map(distances, func(d map[string]int, _ int) float64 {
+		greedy := lo.EveryBy(otherIDs, func(id string) bool { return d[id] == bestDistances[id] })
+		return lo.Ternary(greedy, 1.0, 0.0)
+	})
+	if lo.Sum(weights) == 0 {
+		return uniformWeights(len(nextStates))
+	}
+	return weights
+}
+
+func nearestFoodDistance(topology Topology, p rules.Point, food []rules.Point) int {
+	return lo.Min(lo.Map(food, func(f rules.Point, _ int) int {
+		return topology.Distance(p, f)
+	}))
+}
+
+func otherAliveSnakeIDs(snapshot GameSnapshot) []string {
+	yourID := snapshot.You().ID()
+	return lo.FilterMap(snapshot.AliveSnakes(), func(snake SnakeSnapshot, _ int) (string, bool) {
+		return snake.ID(), snake.ID() != yourID
+	})
+}
+
+func uniformWeights(n int) []float64 {
+	return lo.Map(make([]float64, n), func(_ float64, _ int) float64 { return 1.0 })
+}
+
+// normalizedWeights scales weights to sum to one, falling back to uniform
+// weights when they have no positive mass
+func normalizedWeights(weights []float64) []float64 {
+	total := lo.Sum(weights)
+	if !(total > 0) || math.IsInf(total, 1) {
+		total = float64(len(weights))
+		weights = uniformWeights(len(weights))
+	}
+	return lo.Map(weights, func(w float64, _ int) float64 { return w / total })
+}
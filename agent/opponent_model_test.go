@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// teamStates returns a snapshot seen by "a" whose opponents "b" and "c" are
+// teammates, and two next states in which b is shorter and longer
+func teamStates() (GameSnapshot, []GameSnapshot) {
+	build := func(bBody ...client.Coord) GameSnapshot {
+		b := testSnake("b", bBody...)
+		c := testSnake("c", pt(6, 6), pt(6, 5), pt(6, 4))
+		b.Customizations.Color, c.Customizations.Color = "team", "team"
+		return NewGameSnapshot(testRequest("standard", 7, 7,
+			testSnake("a", pt(0, 2), pt(0, 1), pt(0, 0)), b, c))
+	}
+	snapshot := build(pt(3, 3), pt(3, 2), pt(3, 1))
+	nextStates := []GameSnapshot{
+		build(pt(3, 3), pt(3, 2), pt(3, 1)),
+		build(pt(3, 3), pt(3, 2), pt(3, 1), pt(3, 0)),
+	}
+	return snapshot, nextStates
+}
+
+func TestSoftmaxOpponentModelScoresEachTeamOnce(t *testing.T) {
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+	snapshot, nextStates := teamStates()
+
+	weights := NewSoftmaxOpponentModel(portfolio, 1).Weights(snapshot, nextStates, nil)
+
+	// The team's total length grows by one, so at temperature one the longer
+	// state is e times as likely; counting each teammate would make it e²
+	if ratio := weights[1] / weights[0]; math.Abs(ratio-math.E) > 1e-9 {
+		t.Errorf("weight ratio = %v, want e", ratio)
+	}
+}
+
+func TestSoftmaxOpponentModelIgnoresOurTeam(t *testing.T) {
+	// Our teammate t grows in the second state; opponent b is unchanged
+	build := func(tBody ...client.Coord) GameSnapshot {
+		teammate := testSnake("t", tBody...)
+		teammate.Customizations.Color = "a"
+		return NewGameSnapshot(testRequest("standard", 7, 7,
+			testSnake("a", pt(0, 2), pt(0, 1), pt(0, 0)), teammate,
+			testSnake("b", pt(6, 6), pt(6, 5), pt(6, 4))))
+	}
+	snapshot := build(pt(3, 3), pt(3, 2), pt(3, 1))
+	nextStates := []GameSnapshot{
+		build(pt(3, 3), pt(3, 2), pt(3, 1)),
+		build(pt(3, 3), pt(3, 2), pt(3, 1), pt(3, 0)),
+	}
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+
+	weights := NewSoftmaxOpponentModel(portfolio, 1).Weights(snapshot, nextStates, nil)
+	if weights[0] != weights[1] {
+		t.Errorf("weights = %v, want our teammate's growth to be ignored", weights)
+	}
+}
+
+func TestSoftmaxOpponentModelZeroTemperatureIsArgmax(t *testing.T) {
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+	snapshot, nextStates := teamStates()
+
+	for _, temperature := range []float64{0, -1} {
+		weights := NewSoftmaxOpponentModel(portfolio, temperature).Weights(snapshot, nextStates, nil)
+		if weights[0] != 0 || weights[1] != 1 {
+			t.Errorf("temperature %v: weights = %v, want [0 1]", temperature, weights)
+		}
+	}
+}
+
+func TestMCTSSamplesOpponentsFromModel(t *testing.T) {
+	// b is longer and can meet our head at (1, 2) if we move up
+	snapshot := NewGameSnapshot(testRequest("standard", 7, 7,
+		testSnake("a", pt(1, 1), pt(1, 0), pt(0, 0)),
+		testSnake("b", pt(2, 2), pt(3, 2), pt(4, 2), pt(5, 2))))
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+
+	const samples = 100
+	tests := []struct {
+		name          string
+		model         OpponentModel
+		alwaysAttacks bool
+	}{
+		{"paranoid always attacks", NewParanoidOpponentModel(), true},
+		{"uniform sometimes attacks", NewUniformOpponentModel(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{}, WithMCTS(0), WithOpponentModel(tt.model))
+			search := newMCTSSearch(sa, snapshot, rand.New(rand.NewSource(1)))
+			edge, _ := search.newEdge(context.Background(), snapshot, "up")
+
+			attacks := 0
+			for i := 0; i < samples; i++ {
+				for _, move := range search.sampleJointMove(edge) {
+					if move.ID == "b" && move.Move == "left" {
+						attacks++
+					}
+				}
+			}
+			if tt.alwaysAttacks && attacks != samples {
+				t.Errorf("b attacked %d of %d times, want every time", attacks, samples)
+			}
+			if !tt.alwaysAttacks && (attacks == 0 || attacks == samples) {
+				t.Errorf("b attacked %d of %d times, want a mix", attacks, samples)
+			}
+		})
+	}
+}
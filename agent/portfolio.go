@@ -15,6 +15,7 @@ type WeightedHeuristic interface {
 	F() HeuristicFunc
 	Weight() float64
 	NameAndWeight() string
+	Aggregator() ScoreAggregator
 	GetAndResetStats() (uint64, uint64) // Returns (microseconds, evaluations)
 }
 
@@ -26,14 +27,29 @@ func NewPortfolio(heuristics ...WeightedHeuristic) HeuristicPortfolio {
 	return HeuristicPortfolio(heuristics)
 }
 
-func NewHeuristic(weight float64, name string, f HeuristicFunc) WeightedHeuristic {
-	return &weightedHeuristicImpl{
+// HeuristicOption defines a function type for configuring a WeightedHeuristic
+type HeuristicOption func(*weightedHeuristicImpl)
+
+// WithScoreAggregator sets how the heuristic's scores across a move's next states are combined
+func WithScoreAggregator(aggregator ScoreAggregator) HeuristicOption {
+	return func(w *weightedHeuristicImpl) {
+		w.aggregator = aggregator
+	}
+}
+
+func NewHeuristic(weight float64, name string, f HeuristicFunc, opts ...HeuristicOption) WeightedHeuristic {
+	w := &weightedHeuristicImpl{
 		name:        name,
 		f:           f,
 		weight:      weight,
+		aggregator:  NewMeanAggregator(),
 		microsecs:   0,
 		evaluations: 0,
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
 }
 
 // weightedHeuristicImpl represents a heuristic with an associated weight and name.
@@ -41,6 +57,7 @@ type weightedHeuristicImpl struct {
 	name        string
 	f           HeuristicFunc
 	weight      float64
+	aggregator  ScoreAggregator
 	microsecs   uint64
 	evaluations uint64
 }
@@ -68,8 +85,36 @@ func (w *weightedHeuristicImpl) NameAndWeight() string {
 	return fmt.Sprintf("%s, w=%.2f", w.name, w.weight)
 }
 
+func (w *weightedHeuristicImpl) Aggregator() ScoreAggregator {
+	return w.aggregator
+}
+
 func (w *weightedHeuristicImpl) GetAndResetStats() (uint64, uint64) {
 	micros := atomic.SwapUint64(&w.microsecs, 0)
 	evals := atomic.SwapUint64(&w.evaluations, 0)
 	return micros, evals
 }
+
+// TotalWeight returns the sum of the weights of all heuristics in the portfolio.
+func (p HeuristicPortfolio) TotalWeight() float64 {
+	total := 0.0
+	for _, h := range p {
+		total += h.Weight()
+	}
+	return total
+}
+
+// Evaluate scores a snapshot with every heuristic in the portfolio and returns
+// the weighted sum normalized by the total weight, matching the scale of the
+// normalized move scores logged by ChooseMove.
+func (p HeuristicPortfolio) Evaluate(snapshot GameSnapshot) float64 {
+	totalWeight := p.TotalWeight()
+	if totalWeight == 0 {
+		return 0
+	}
+	score := 0.0
+	for _, h := range p {
+		score += h.F()(snapshot) * h.Weight()
+	}
+	return score / totalWeight
+}
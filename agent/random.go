@@ -0,0 +1,21 @@
+package agent
+
+import (
+	"math/rand"
+)
+
+// moveRand returns the random number generator for choosing a move in
+// snapshot. Each move gets its own generator so concurrent requests don't
+// share one, and so that with a fixed seed the same game replays the same
+// moves.
+func (sa *SnakeAgent) moveRand(snapshot GameSnapshot) *rand.Rand {
+	if sa.SeedFromGame {
+		you := snapshot.You()
+		seed := splitmix64(uint64(sa.Seed) ^ idKey(snapshot.GameID()) ^ idKey(you.ID()) ^ uint64(snapshot.Turn()))
+		return rand.New(rand.NewSource(int64(seed)))
+	}
+
+	sa.randMu.Lock()
+	defer sa.randMu.Unlock()
+	return rand.New(rand.NewSource(sa.Rand.Int63()))
+}
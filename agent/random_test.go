@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestSeededMovesReplay(t *testing.T) {
+	request := testRequest("standard", 11, 11,
+		testSnake("a", pt(5, 5), pt(5, 4), pt(5, 3)),
+		testSnake("b", pt(6, 7), pt(7, 7), pt(8, 7), pt(9, 7)),
+		testSnake("c", pt(2, 2), pt(2, 1), pt(2, 0)))
+	request.Board.Food = []client.Coord{pt(4, 6), pt(9, 1)}
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+
+	tests := []struct {
+		name string
+		opts []SnakeAgentOption
+	}{
+		{"one-ply", nil},
+		{"mcts", []SnakeAgentOption{WithMCTS(200)}},
+		{"expectimax", []SnakeAgentOption{WithExpectimax(2)}},
+		// Uncapped searches are capped by the seed, not stopped by the deadline
+		{"uncapped mcts", []SnakeAgentOption{WithMCTS(0)}},
+		{"uncapped expectimax", []SnakeAgentOption{WithExpectimax(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := NewMemoryTraceSink()
+			opts := append([]SnakeAgentOption{WithSeed(7), WithSeedFromGame(true), WithTraceSink(sink)}, tt.opts...)
+			sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{}, opts...)
+
+			for i := 0; i < 2; i++ {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				sa.ChooseMoveWithContext(ctx, sa.NewGameSnapshot(request))
+				cancel()
+			}
+
+			traces := sink.Traces()
+			first, second := traces[0], traces[1]
+			if first.ChosenMove != second.ChosenMove {
+				t.Errorf("chose %s then %s", first.ChosenMove, second.ChosenMove)
+			}
+			if !reflect.DeepEqual(first.Scores, second.Scores) {
+				t.Errorf("scores %v then %v", first.Scores, second.Scores)
+			}
+			if first.MCTSIterations != second.MCTSIterations || first.SearchDepth != second.SearchDepth {
+				t.Errorf("searched %d iterations to depth %d, then %d to depth %d",
+					first.MCTSIterations, first.SearchDepth, second.MCTSIterations, second.SearchDepth)
+			}
+		})
+	}
+}
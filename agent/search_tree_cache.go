@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// Stored trees are dropped once their game has gone quiet for
+// searchTreeTTL, and beyond maxSearchTreeGames only the most recently
+// searched games keep theirs, so games that never reach /end cannot pile up.
+const (
+	searchTreeTTL      = 10 * time.Minute
+	maxSearchTreeGames = 64
+)
+
+// searchTreeCache keeps the MCTS tree searched on the previous turn of each
+// game so the subtree matching the moves actually played can be reused.
+// Trees are keyed by game ID and then by snake ID, since one server may play
+// several snakes in the same game.
+type searchTreeCache struct {
+	mu    sync.Mutex
+	games map[string]*gameSearchTrees
+	now   func() time.Time
+}
+
+// gameSearchTrees holds the trees of one game's snakes
+type gameSearchTrees struct {
+	trees    map[string]*mctsNode
+	lastUsed time.Time
+}
+
+func newSearchTreeCache() *searchTreeCache {
+	return &searchTreeCache{games: make(map[string]*gameSearchTrees), now: time.Now}
+}
+
+// store remembers the tree searched for snapshot
+func (c *searchTreeCache) store(snapshot GameSnapshot, root *mctsNode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	game, found := c.games[snapshot.GameID()]
+	if !found {
+		game = &gameSearchTrees{trees: make(map[string]*mctsNode)}
+		c.games[snapshot.GameID()] = game
+	}
+	game.trees[snapshot.You().ID()] = root
+	game.lastUsed = now
+	c.evict(now)
+}
+
+// evict drops expired games, then the least recently used games over the cap
+func (c *searchTreeCache) evict(now time.Time) {
+	for gameID, game := range c.games {
+		if now.Sub(game.lastUsed) > searchTreeTTL {
+			delete(c.games, gameID)
+		}
+	}
+	for len(c.games) > maxSearchTreeGames {
+		oldest := lo.MinBy(lo.Keys(c.games), func(a, b string) bool {
+			return c.games[a].lastUsed.Before(c.games[b].lastUsed)
+		})
+		delete(c.games, oldest)
+	}
+}
+
+// take removes the stored tree for snapshot's game and snake and returns the
+// child node that matches snapshot, with its state replaced by snapshot, or
+// nil if the moves played were never expanded or the tree has expired.
+func (c *searchTreeCache) take(snapshot GameSnapshot) *mctsNode {
+	c.mu.Lock()
+	var root *mctsNode
+	if game, found := c.games[snapshot.GameID()]; found {
+		if c.now().Sub(game.lastUsed) <= searchTreeTTL {
+			root = game.trees[snapshot.You().ID()]
+		}
+		delete(game.trees, snapshot.You().ID())
+	}
+	c.mu.Unlock()
+
+	if root == nil || root.snapshot.Turn()+1 != snapshot.Turn() {
+		return nil
+	}
+	for _, edge := range root.edges {
+		for _, child := range edge.children {
+			if sameSnakes(child.snapshot, snapshot) {
+				// The real game may have spawned food we did not simulate
+				child.snapshot = snapshot
+				return child
+			}
+		}
+	}
+	return nil
+}
+
+// endGame discards every tree stored for the game
+func (c *searchTreeCache) endGame(gameID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.games, gameID)
+}
+
+// sameSnakes reports whether both snapshots have the same alive snakes with
+// identical bodies and health.
+func sameSnakes(a, b GameSnapshot) bool {
+	aSnakes, bSnakes := a.AliveSnakes(), b.AliveSnakes()
+	if len(aSnakes) != len(bSnakes) {
+		return false
+	}
+	bByID := lo.KeyBy(bSnakes, func(s SnakeSnapshot) string { return s.ID() })
+	return lo.EveryBy(aSnakes, func(s SnakeSnapshot) bool {
+		other, found := bByID[s.ID()]
+		return found && other.Health() == s.Health() && slices.Equal(other.Body(), s.Body())
+	})
+}
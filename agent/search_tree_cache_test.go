@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// gameSnapshot returns a snapshot of a two-snake game with the given ID
+func gameSnapshot(gameID string) GameSnapshot {
+	request := testRequest("standard", 7, 7,
+		testSnake("a", pt(1, 1), pt(1, 0), pt(0, 0)),
+		testSnake("b", pt(5, 5), pt(5, 6), pt(6, 6)))
+	request.Game.ID = gameID
+	return NewGameSnapshot(request)
+}
+
+// fakeClockCache returns a cache whose clock reads *now
+func fakeClockCache(now *time.Time) *searchTreeCache {
+	cache := newSearchTreeCache()
+	cache.now = func() time.Time { return *now }
+	return cache
+}
+
+func TestSearchTreeCacheExpires(t *testing.T) {
+	tests := []struct {
+		elapsed   time.Duration
+		wantFound bool
+	}{
+		{time.Minute, true},
+		{searchTreeTTL + time.Second, false},
+	}
+	for _, tt := range tests {
+		now := time.Unix(0, 0)
+		cache := fakeClockCache(&now)
+		snapshot := gameSnapshot("game")
+		cache.store(snapshot, &mctsNode{snapshot: snapshot})
+
+		// The next turn's snapshot only matches an expanded child, so check
+		// what take would read rather than its result
+		now = now.Add(tt.elapsed)
+		cache.store(gameSnapshot("other"), &mctsNode{})
+		if _, found := cache.games["game"]; found != tt.wantFound {
+			t.Errorf("after %v: found = %v, want %v", tt.elapsed, found, tt.wantFound)
+		}
+	}
+}
+
+func TestSearchTreeCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := fakeClockCache(&now)
+	for i := 0; i < maxSearchTreeGames+2; i++ {
+		now = now.Add(time.Millisecond)
+		cache.store(gameSnapshot(fmt.Sprint(i)), &mctsNode{})
+	}
+
+	if len(cache.games) != maxSearchTreeGames {
+		t.Errorf("kept %d games, want %d", len(cache.games), maxSearchTreeGames)
+	}
+	for i, wantFound := range map[int]bool{0: false, 1: false, 2: true, maxSearchTreeGames + 1: true} {
+		if _, found := cache.games[fmt.Sprint(i)]; found != wantFound {
+			t.Errorf("game %d: found = %v, want %v", i, found, wantFound)
+		}
+	}
+}
+
+func TestSearchTreeReuseRequiresMCTS(t *testing.T) {
+	portfolio := NewPortfolio(NewHeuristic(1, "length", lengthHeuristic))
+	tests := []struct {
+		name      string
+		opts      []SnakeAgentOption
+		wantReuse bool
+		wantPanic bool
+	}{
+		{"one-ply default", nil, false, false},
+		{"mcts default", []SnakeAgentOption{WithMCTS(10)}, true, false},
+		{"mcts disabled", []SnakeAgentOption{WithMCTS(10), WithSearchTreeReuse(false)}, false, false},
+		{"enabled before mcts", []SnakeAgentOption{WithSearchTreeReuse(true), WithMCTS(10)}, true, false},
+		{"expectimax enabled", []SnakeAgentOption{WithExpectimax(2), WithSearchTreeReuse(true)}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if panicked := recover() != nil; panicked != tt.wantPanic {
+					t.Errorf("panicked = %v, want %v", panicked, tt.wantPanic)
+				}
+			}()
+			sa := NewSnakeAgent(portfolio, client.SnakeMetadataResponse{}, tt.opts...)
+			if sa.ReuseSearchTrees != tt.wantReuse {
+				t.Errorf("ReuseSearchTrees = %v, want %v", sa.ReuseSearchTrees, tt.wantReuse)
+			}
+		})
+	}
+}
@@ -16,7 +16,37 @@ type SnakeSnapshot interface {
 	Head() rules.Point
 	Length() int
 	LastShout() string
-	ConsideredMoves() []rules.SnakeMove
+	ConsideredMoves(opts ...ConsideredMovesOption) []rules.SnakeMove
+	MoveTarget(move string) rules.Point
+}
+
+// HeadToHeadPolicy sets how ConsideredMoves treats moves into cells that the
+// head of an equal or longer snake from another team could also enter
+type HeadToHeadPolicy int
+
+const (
+	// HeadToHeadIgnore treats contested moves like any other
+	HeadToHeadIgnore HeadToHeadPolicy = iota
+	// HeadToHeadDeprioritize keeps contested moves but makes them
+	// contestedMoveWeight times as likely, both for the agent's own move and
+	// in the agent's model of the snakes it simulates. ConsideredMoves returns
+	// every move; SnakeAgent applies the weights once it has scored them.
+	HeadToHeadDeprioritize
+	// HeadToHeadExclude drops contested moves unless every move is contested
+	HeadToHeadExclude
+)
+
+type consideredMovesConfig struct {
+	headToHead HeadToHeadPolicy
+}
+
+type ConsideredMovesOption func(*consideredMovesConfig)
+
+// AvoidHeadToHead sets how contested moves are treated. Defaults to HeadToHeadIgnore.
+func AvoidHeadToHead(policy HeadToHeadPolicy) ConsideredMovesOption {
+	return func(c *consideredMovesConfig) {
+		c.headToHead = policy
+	}
 }
 
 // SnakeSnapshot interface implementation
@@ -68,12 +98,17 @@ func (s *snakeSnapshotImpl) LastShout() string {
 	return s.stats.lastShout
 }
 
-func (s *snakeSnapshotImpl) ConsideredMoves() []rules.SnakeMove {
+func (s *snakeSnapshotImpl) ConsideredMoves(opts ...ConsideredMovesOption) []rules.SnakeMove {
+	config := consideredMovesConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
 	possibleMoveStrs := []string{"up", "down", "left", "right"}
 
 	isPassable := func(move string) bool {
 		board := s.gameSnapshot.Board()
-		target := s.getTargetPoint(move)
+		target := s.MoveTarget(move)
 		if target.X < 0 || target.X >= board.Width || target.Y < 0 || target.Y >= board.Height {
 			return false
 		}
@@ -88,21 +123,36 @@ func (s *snakeSnapshotImpl) ConsideredMoves() []rules.SnakeMove {
 		return []rules.SnakeMove{{ID: s.ID(), Move: "up"}}
 	}
 
+	if config.headToHead == HeadToHeadExclude {
+		threats := HeadThreatsTo(s.gameSnapshot, s.gameSnapshot.Team(s.ID()))
+		safe := lo.Reject(consideredMoves, func(move rules.SnakeMove, _ int) bool {
+			return isContested(threats, s.MoveTarget(move.Move), s.Length())
+		})
+		if len(safe) > 0 {
+			consideredMoves = safe
+		}
+	}
+
 	return consideredMoves
 }
 
-func (s *snakeSnapshotImpl) getTargetPoint(move string) rules.Point {
+// MoveTarget returns the point the snake's head moves to with the given
+// move, which is off the board if the move leaves it
+func (s *snakeSnapshotImpl) MoveTarget(move string) rules.Point {
 	head := s.Head()
+	var delta rules.Point
 	switch move {
 	case "up":
-		return rules.Point{X: head.X, Y: head.Y + 1}
+		delta = rules.Point{X: 0, Y: 1}
 	case "down":
-		return rules.Point{X: head.X, Y: head.Y - 1}
+		delta = rules.Point{X: 0, Y: -1}
 	case "left":
-		return rules.Point{X: head.X - 1, Y: head.Y}
+		delta = rules.Point{X: -1, Y: 0}
 	case "right":
-		return rules.Point{X: head.X + 1, Y: head.Y}
+		delta = rules.Point{X: 1, Y: 0}
 	default:
 		return head
 	}
+	target, _ := s.gameSnapshot.Topology().Step(head, delta)
+	return target
 }
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/samber/lo"
+)
+
+// TeamResolver decides which snakes in a request play on the same team
+type TeamResolver interface {
+	// Teams returns the team key of every snake in the request, including
+	// You; snakes with equal keys are allies. Snakes missing from the map
+	// play alone.
+	Teams(request *client.SnakeRequest) map[string]string
+}
+
+// TeamShouter is implemented by team resolvers whose allies recognise each
+// other by what they shout. The agent shouts Shout on every move.
+type TeamShouter interface {
+	Shout(gameID, snakeID string, turn int) string
+}
+
+// requestSnakes returns every snake in the request, including You even if it
+// is missing from the board
+func requestSnakes(request *client.SnakeRequest) []client.Snake {
+	snakes := request.Board.Snakes
+	if !lo.ContainsBy(snakes, func(s client.Snake) bool { return s.ID == request.You.ID }) {
+		snakes = append(snakes, request.You)
+	}
+	return snakes
+}
+
+// soloTeam is the team key of a snake that has no allies
+func soloTeam(snakeID string) string {
+	return "snake:" + snakeID
+}
+
+// ColorTeamResolver puts snakes of the same color on the same team. Snakes
+// without a color play alone.
+type ColorTeamResolver struct{}
+
+func NewColorTeamResolver() TeamResolver {
+	return ColorTeamResolver{}
+}
+
+func (ColorTeamResolver) Teams(request *client.SnakeRequest) map[string]string {
+	return lo.SliceToMap(requestSnakes(request), func(s client.Snake) (string, string) {
+		color := s.Customizations.Color
+		return s.ID, lo.Ternary(color == "", soloTeam(s.ID), "color:"+color)
+	})
+}
+
+// SquadTeamResolver puts snakes of the same squad on the same team. Snakes
+// without a squad play alone.
+type SquadTeamResolver struct{}
+
+func NewSquadTeamResolver() TeamResolver {
+	return SquadTeamResolver{}
+}
+
+func (SquadTeamResolver) Teams(request *client.SnakeRequest) map[string]string {
+	return lo.SliceToMap(requestSnakes(request), func(s client.Snake) (string, string) {
+		return s.ID, lo.Ternary(s.Squad == "", soloTeam(s.ID), "squad:"+s.Squad)
+	})
+}
+
+// AllowListTeamResolver puts You on a team with every snake whose name or ID
+// is listed. Every other snake plays alone.
+type AllowListTeamResolver struct {
+	Allies map[string]bool
+}
+
+func NewAllowListTeamResolver(namesOrIDs ...string) TeamResolver {
+	return AllowListTeamResolver{Allies: lo.SliceToMap(namesOrIDs, func(s string) (string, bool) {
+		return s, true
+	})}
+}
+
+func (r AllowListTeamResolver) Teams(request *client.SnakeRequest) map[string]string {
+	return lo.SliceToMap(requestSnakes(request), func(s client.Snake) (string, string) {
+		ally := s.ID == request.You.ID || r.Allies[s.ID] || r.Allies[s.Name]
+		return s.ID, lo.Ternary(ally, "allies", soloTeam(s.ID))
+	})
+}
+
+// LoadAllowListTeamResolver reads an allow-list of ally names or IDs from a
+// config file with one per line. Blank lines and lines starting with # are
+// ignored.
+func LoadAllowListTeamResolver(path string) (TeamResolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading ally allow-list: %w", err)
+	}
+	allies := lo.FilterMap(strings.Split(string(data), "\n"), func(line string, _ int) (string, bool) {
+		line = strings.TrimSpace(line)
+		return line, line != "" && !strings.HasPrefix(line, "#")
+	})
+	return NewAllowListTeamResolver(allies...), nil
+}
+
+// ShoutHandshakeTeamResolver puts You on a team with every snake whose last
+// shout is the handshake token for it on the previous turn. Tokens are an
+// HMAC of the game, snake and turn with a shared secret, so an opponent
+// can't forge one or replay one it has seen, and allies are checked afresh
+// every turn. Allies aren't recognised on turn 0, before anyone has shouted.
+type ShoutHandshakeTeamResolver struct {
+	Secret []byte
+}
+
+func NewShoutHandshakeTeamResolver(secret []byte) *ShoutHandshakeTeamResolver {
+	if len(secret) == 0 {
+		panic("Shout handshake requires a secret")
+	}
+	return &ShoutHandshakeTeamResolver{Secret: secret}
+}
+
+// Shout returns the handshake token a snake shouts in reply to a turn
+func (r *ShoutHandshakeTeamResolver) Shout(gameID, snakeID string, turn int) string {
+	mac := hmac.New(sha256.New, r.Secret)
+	fmt.Fprintf(mac, "%s/%s/%d", gameID, snakeID, turn)
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (r *ShoutHandshakeTeamResolver) Teams(request *client.SnakeRequest) map[string]string {
+	// The shouts in a request are the replies to the previous turn
+	return lo.SliceToMap(requestSnakes(request), func(s client.Snake) (string, string) {
+		token := r.Shout(request.Game.ID, s.ID, request.Turn-1)
+		ally := s.ID == request.You.ID || hmac.Equal([]byte(s.Shout), []byte(token))
+		return s.ID, lo.Ternary(ally, "allies", soloTeam(s.ID))
+	})
+}
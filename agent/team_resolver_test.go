@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/samber/lo"
+)
+
+// partialTeamResolver only knows the team of You
+type partialTeamResolver struct{}
+
+func (partialTeamResolver) Teams(request *client.SnakeRequest) map[string]string {
+	return map[string]string{request.You.ID: "us"}
+}
+
+// teamRequest has three snakes, "a" (You), "b" and "c", with the given colors
+// and shouts for b and c
+func teamRequest(colors, shouts [3]string) *client.SnakeRequest {
+	snakes := []client.Snake{
+		testSnake("a", pt(1, 1), pt(1, 0)),
+		testSnake("b", pt(3, 1), pt(3, 0)),
+		testSnake("c", pt(5, 1), pt(5, 0)),
+	}
+	for i := range snakes {
+		snakes[i].Customizations.Color = colors[i]
+		snakes[i].Shout = shouts[i]
+	}
+	request := testRequest("standard", 7, 7, snakes...)
+	request.Turn = 5
+	return request
+}
+
+func teammateIDs(snapshot GameSnapshot) []string {
+	ids := lo.Map(snapshot.Teammates(), func(s SnakeSnapshot, _ int) string { return s.ID() })
+	slices.Sort(ids)
+	return ids
+}
+
+func TestTeamResolvers(t *testing.T) {
+	handshake := NewShoutHandshakeTeamResolver([]byte("secret"))
+	forger := NewShoutHandshakeTeamResolver([]byte("guess"))
+
+	tests := []struct {
+		name     string
+		resolver TeamResolver
+		request  *client.SnakeRequest
+		want     []string
+	}{
+		{"same color", NewColorTeamResolver(), teamRequest([3]string{"red", "red", "blue"}, [3]string{}), []string{"b"}},
+		{"no color plays alone", NewColorTeamResolver(), teamRequest([3]string{"", "", ""}, [3]string{}), []string{}},
+		{"allow-list by name", NewAllowListTeamResolver("c"), teamRequest([3]string{}, [3]string{}), []string{"c"}},
+		{"handshake", handshake, teamRequest([3]string{}, [3]string{"", handshake.Shout("test-game", "b", 4), ""}), []string{"b"}},
+		{"replayed handshake", handshake, teamRequest([3]string{}, [3]string{"", handshake.Shout("test-game", "b", 3), ""}), []string{}},
+		{"copied handshake", handshake, teamRequest([3]string{}, [3]string{"", "", handshake.Shout("test-game", "b", 4)}), []string{}},
+		{"forged handshake", handshake, teamRequest([3]string{}, [3]string{"", forger.Shout("test-game", "b", 4), ""}), []string{}},
+		{"missing teams play alone", partialTeamResolver{}, teamRequest([3]string{}, [3]string{}), []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := NewGameSnapshotWithTeams(tt.request, tt.resolver)
+			if got := teammateIDs(snapshot); !slices.Equal(got, tt.want) {
+				t.Errorf("teammates = %v, want %v", got, tt.want)
+			}
+			if snapshot.Team("b") == snapshot.Team("c") && !slices.Contains(tt.want, "b") {
+				t.Errorf("b and c share team %q", snapshot.Team("b"))
+			}
+		})
+	}
+}
+
+func TestAgentShoutsHandshake(t *testing.T) {
+	// a shouts on turn 4; b sees the shout in its turn 5 request
+	resolver := NewShoutHandshakeTeamResolver([]byte("secret"))
+	sa := NewSnakeAgent(NewPortfolio(NewHeuristic(1, "length", lengthHeuristic)), client.SnakeMetadataResponse{},
+		WithTeamResolver(resolver))
+	request := teamRequest([3]string{}, [3]string{})
+	request.Turn = 4
+	response := sa.ChooseMoveWithContext(context.Background(), sa.NewGameSnapshot(request))
+
+	request = teamRequest([3]string{}, [3]string{response.Shout, "", ""})
+	request.You = request.Board.Snakes[1]
+	if got := teammateIDs(sa.NewGameSnapshot(request)); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("b's teammates = %v, want [a]", got)
+	}
+}
+
+func TestLoadAllowListTeamResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allies.txt")
+	if err := os.WriteFile(path, []byte("# our squad\nb\n\n  c  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := LoadAllowListTeamResolver(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := NewGameSnapshotWithTeams(teamRequest([3]string{}, [3]string{}), resolver)
+	if got := teammateIDs(snapshot); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("teammates = %v, want [b c]", got)
+	}
+
+	if _, err := LoadAllowListTeamResolver(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("loading a missing file succeeded")
+	}
+}
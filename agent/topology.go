@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// Topology describes how cells of the board connect to each other
+type Topology interface {
+	Name() string
+	// Step returns the point one move from p along delta, and false if that
+	// move leaves the board
+	Step(p, delta rules.Point) (rules.Point, bool)
+	// Distance returns the fewest moves between a and b on an empty board
+	Distance(a, b rules.Point) int
+}
+
+// NewTopology returns the topology used by the named ruleset
+func NewTopology(rulesetName string, width, height int) Topology {
+	if GameModeFor(rulesetName).Wrapped {
+		return WrappedTopology{Width: width, Height: height}
+	}
+	return BoundedTopology{Width: width, Height: height}
+}
+
+// BoundedTopology is the standard board, where moving past an edge is fatal
+type BoundedTopology struct {
+	Width, Height int
+}
+
+func (t BoundedTopology) Name() string {
+	return "bounded"
+}
+
+func (t BoundedTopology) Step(p, delta rules.Point) (rules.Point, bool) {
+	next := rules.Point{X: p.X + delta.X, Y: p.Y + delta.Y}
+	return next, next.X >= 0 && next.X < t.Width && next.Y >= 0 && next.Y < t.Height
+}
+
+func (t BoundedTopology) Distance(a, b rules.Point) int {
+	return manhattanDistance(a, b)
+}
+
+// WrappedTopology is a torus, where moving past an edge comes back in on the opposite edge
+type WrappedTopology struct {
+	Width, Height int
+}
+
+func (t WrappedTopology) Name() string {
+	return "wrapped"
+}
+
+func (t WrappedTopology) Step(p, delta rules.Point) (rules.Point, bool) {
+	return rules.Point{
+		X: ((p.X+delta.X)%t.Width + t.Width) % t.Width,
+		Y: ((p.Y+delta.Y)%t.Height + t.Height) % t.Height,
+	}, true
+}
+
+func (t WrappedTopology) Distance(a, b rules.Point) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	return min(dx, t.Width-dx) + min(dy, t.Height-dy)
+}
+
+func manhattanDistance(a, b rules.Point) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
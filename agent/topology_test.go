@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+func TestTopologies(t *testing.T) {
+	bounded := NewTopology(rules.GameTypeStandard, 7, 5)
+	wrapped := NewTopology(rules.GameTypeWrappedConstrictor, 7, 5)
+	if bounded.Name() != "bounded" || wrapped.Name() != "wrapped" {
+		t.Fatalf("topologies = %s, %s, want bounded, wrapped", bounded.Name(), wrapped.Name())
+	}
+
+	left, up := rules.Point{X: -1, Y: 0}, rules.Point{X: 0, Y: 1}
+	steps := []struct {
+		topology Topology
+		from     rules.Point
+		delta    rules.Point
+		want     rules.Point
+		onBoard  bool
+	}{
+		{bounded, rules.Point{X: 3, Y: 2}, left, rules.Point{X: 2, Y: 2}, true},
+		{bounded, rules.Point{X: 0, Y: 2}, left, rules.Point{X: -1, Y: 2}, false},
+		{bounded, rules.Point{X: 3, Y: 4}, up, rules.Point{X: 3, Y: 5}, false},
+		{wrapped, rules.Point{X: 3, Y: 2}, left, rules.Point{X: 2, Y: 2}, true},
+		{wrapped, rules.Point{X: 0, Y: 2}, left, rules.Point{X: 6, Y: 2}, true},
+		{wrapped, rules.Point{X: 3, Y: 4}, up, rules.Point{X: 3, Y: 0}, true},
+	}
+	for _, tt := range steps {
+		if got, onBoard := tt.topology.Step(tt.from, tt.delta); got != tt.want || onBoard != tt.onBoard {
+			t.Errorf("%s step %v from %v = %v, %v, want %v, %v",
+				tt.topology.Name(), tt.delta, tt.from, got, onBoard, tt.want, tt.onBoard)
+		}
+	}
+
+	distances := []struct {
+		topology Topology
+		a, b     rules.Point
+		want     int
+	}{
+		{bounded, rules.Point{X: 0, Y: 0}, rules.Point{X: 6, Y: 4}, 10},
+		{wrapped, rules.Point{X: 0, Y: 0}, rules.Point{X: 6, Y: 4}, 2},
+		{wrapped, rules.Point{X: 1, Y: 1}, rules.Point{X: 4, Y: 3}, 3 + 2},
+	}
+	for _, tt := range distances {
+		if got := tt.topology.Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s distance %v to %v = %d, want %d", tt.topology.Name(), tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCornerNeighbours(t *testing.T) {
+	tests := []struct {
+		ruleset string
+		want    []rules.Point
+	}{
+		{rules.GameTypeStandard, []rules.Point{{X: 0, Y: 1}, {X: 1, Y: 0}}},
+		{rules.GameTypeWrapped, []rules.Point{{X: 0, Y: 1}, {X: 0, Y: 6}, {X: 1, Y: 0}, {X: 6, Y: 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ruleset, func(t *testing.T) {
+			request := testRequest(tt.ruleset, 7, 7,
+				testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)),
+				testSnake("b", pt(5, 5), pt(5, 6), pt(6, 6)))
+			board := NewGameSnapshot(request).Board()
+
+			var got []rules.Point
+			for _, cell := range board.Cells[0][0].Neighbours(board) {
+				got = append(got, cell.Coordinates())
+			}
+			slices.SortFunc(got, func(a, b rules.Point) int { return (a.X-b.X)*100 + a.Y - b.Y })
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("neighbours of the corner = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// DecisionTrace records how a move was chosen on one turn. Per-move values
+// are keyed by move; moves whose score could not be computed before the
+// deadline are left out of Scores.
+type DecisionTrace struct {
+	GameID          string             `json:"gameId"`
+	SnakeID         string             `json:"snakeId"`
+	Turn            int                `json:"turn"`
+	SearchMode      string             `json:"searchMode"`
+	ConsideredMoves []string           `json:"consideredMoves"`
+	NextStates      map[string]int     `json:"nextStates,omitempty"`
+	Heuristics      []HeuristicTrace   `json:"heuristics,omitempty"`
+	Scores          map[string]float64 `json:"scores,omitempty"`
+	Selector        string             `json:"selector,omitempty"`
+	Probabilities   map[string]float64 `json:"probabilities"`
+	ChosenMove      string             `json:"chosenMove"`
+
+	// MCTS
+	MCTSIterations int                `json:"mctsIterations,omitempty"`
+	RootVisits     map[string]int     `json:"rootVisits,omitempty"`
+	RootValues     map[string]float64 `json:"rootValues,omitempty"`
+	ReusedVisits   int                `json:"reusedVisits,omitempty"`
+
+	// Expectimax
+	SearchDepth int   `json:"searchDepth,omitempty"`
+	SearchNodes int64 `json:"searchNodes,omitempty"`
+
+	// Timings
+	DeadlineReached     bool             `json:"deadlineReached"`
+	Duration            time.Duration    `json:"durationNanos"`
+	HeuristicStats      []HeuristicStats `json:"heuristicStats,omitempty"`
+	TranspositionHits   uint64           `json:"transpositionHits,omitempty"`
+	TranspositionMisses uint64           `json:"transpositionMisses,omitempty"`
+}
+
+// HeuristicTrace holds one heuristic's aggregated scores for each move
+type HeuristicTrace struct {
+	Name       string             `json:"name"`
+	Weight     float64            `json:"weight"`
+	Aggregator string             `json:"aggregator"`
+	Raw        map[string]float64 `json:"raw"`
+	Weighted   map[string]float64 `json:"weighted"`
+}
+
+// HeuristicStats holds the evaluation count and time spent in a heuristic during one turn
+type HeuristicStats struct {
+	Name        string `json:"name"`
+	Evaluations uint64 `json:"evaluations"`
+	Micros      uint64 `json:"micros"`
+}
+
+// finiteValues maps moves to values, leaving out values that are not finite
+// so the trace can always be encoded as JSON
+func finiteValues(moves []string, values []float64) map[string]float64 {
+	result := make(map[string]float64, len(moves))
+	for i, move := range moves {
+		if !math.IsInf(values[i], 0) && !math.IsNaN(values[i]) {
+			result[move] = values[i]
+		}
+	}
+	return result
+}
+
+// TraceSink receives the decision trace of every move the agent makes
+type TraceSink interface {
+	Emit(trace DecisionTrace)
+}
+
+// TextTraceSink logs traces in the agent's human-readable per-turn format
+type TextTraceSink struct{}
+
+func NewTextTraceSink() TraceSink {
+	return TextTraceSink{}
+}
+
+func (TextTraceSink) Emit(t DecisionTrace) {
+	movesLine := func(values func(move string) string) string {
+		return strings.Join(lo.Map(t.ConsideredMoves, func(move string, _ int) string {
+			return move + "=" + values(move)
+		}), ", ")
+	}
+	score := func(m map[string]float64) func(string) string {
+		return func(move string) string {
+			value, found := m[move]
+			return fmt.Sprintf("%6.1f", lo.Ternary(found, value, math.Inf(-1)))
+		}
+	}
+
+	log.Printf("\n\n ### Start Turn %d: Considered Moves = %v", t.Turn, t.ConsideredMoves)
+
+	for _, h := range t.Heuristics {
+		label := fmt.Sprintf("%s, w=%.2f, %s", h.Name, h.Weight, h.Aggregator)
+		log.Printf("MoveScores for %25s: %s", label, movesLine(score(h.Raw)))
+	}
+
+	switch t.SearchMode {
+	case SearchMCTS.String():
+		if t.ReusedVisits > 0 {
+			log.Printf("### %36s: %d visits", "Reused Search Tree", t.ReusedVisits)
+		}
+		log.Printf("### %36s: %d iterations", "MCTS", t.MCTSIterations)
+		log.Printf("### %36s: %s", "Root Visits", movesLine(func(move string) string {
+			return fmt.Sprintf("%6d", t.RootVisits[move])
+		}))
+		log.Printf("### %36s: %s", "Root Mean Values", movesLine(score(t.RootValues)))
+	case SearchExpectimax.String():
+		log.Printf("### %36s: depth=%d, nodes=%d", "Expectimax", t.SearchDepth, t.SearchNodes)
+		log.Printf("### %36s: %s", "Move Values", movesLine(score(t.Scores)))
+	default:
+		if len(t.Heuristics) > 0 {
+			log.Printf("### %36s: %s", "Normalized Weights", movesLine(score(t.Scores)))
+		}
+	}
+
+	if t.DeadlineReached {
+		log.Printf("### Move deadline reached, using best move found so far")
+	}
+	if t.Selector != "" {
+		log.Printf("### %36s: %s", "Move Probabilities ("+t.Selector+")", movesLine(func(move string) string {
+			return fmt.Sprintf("%5.1f%%", t.Probabilities[move]*100)
+		}))
+	}
+
+	if len(t.HeuristicStats) > 0 || t.TranspositionHits+t.TranspositionMisses > 0 {
+		log.Printf("### Performance Stats:")
+		for _, h := range t.HeuristicStats {
+			avgMicros := float64(h.Micros) / float64(h.Evaluations)
+			totalMillis := float64(h.Micros) / 1000.0
+			log.Printf("###   %25s: %6d evals, %8.2f µs/eval, %8.2f ms total",
+				h.Name, h.Evaluations, avgMicros, totalMillis)
+		}
+		if lookups := t.TranspositionHits + t.TranspositionMisses; lookups > 0 {
+			log.Printf("###   %25s: %6d hits, %6d misses, %6.1f%% hit rate",
+				"transposition table", t.TranspositionHits, t.TranspositionMisses, float64(t.TranspositionHits)/float64(lookups)*100)
+		}
+	}
+}
+
+// JSONLinesTraceSink writes each trace as one line of JSON
+type JSONLinesTraceSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer // the file opened by NewJSONLinesFileTraceSink, if any
+}
+
+func NewJSONLinesTraceSink(w io.Writer) *JSONLinesTraceSink {
+	return &JSONLinesTraceSink{encoder: json.NewEncoder(w)}
+}
+
+// NewJSONLinesFileTraceSink appends traces to the file at path, creating it
+// if needed. Close the sink to close the file.
+func NewJSONLinesFileTraceSink(path string) (*JSONLinesTraceSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	sink := NewJSONLinesTraceSink(file)
+	sink.closer = file
+	return sink, nil
+}
+
+// Close closes the file the sink writes to, if it opened one. The writer
+// given to NewJSONLinesTraceSink is left to its owner.
+func (s *JSONLinesTraceSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closer == nil {
+		return nil
+	}
+	err := s.closer.Close()
+	s.closer = nil
+	return err
+}
+
+func (s *JSONLinesTraceSink) Emit(trace DecisionTrace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.encoder.Encode(trace); err != nil {
+		log.Printf("Error writing decision trace: %v", err)
+	}
+}
+
+// MemoryTraceSink keeps every trace in memory
+type MemoryTraceSink struct {
+	mu     sync.Mutex
+	traces []DecisionTrace
+}
+
+func NewMemoryTraceSink() *MemoryTraceSink {
+	return &MemoryTraceSink{}
+}
+
+func (s *MemoryTraceSink) Emit(trace DecisionTrace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traces = append(s.traces, trace)
+}
+
+// Traces returns a copy of the traces emitted so far
+func (s *MemoryTraceSink) Traces() []DecisionTrace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DecisionTrace(nil), s.traces...)
+}
+
+// MultiTraceSink emits every trace to each of its sinks
+type MultiTraceSink []TraceSink
+
+func NewMultiTraceSink(sinks ...TraceSink) TraceSink {
+	return MultiTraceSink(sinks)
+}
+
+func (m MultiTraceSink) Emit(trace DecisionTrace) {
+	for _, sink := range m {
+		sink.Emit(trace)
+	}
+}
+
+// Close closes every sink that can be closed, returning the errors joined
+func (m MultiTraceSink) Close() error {
+	return closeTraceSinks(m...)
+}
+
+// closeTraceSinks closes each sink that implements io.Closer
+func closeTraceSinks(sinks ...TraceSink) error {
+	return errors.Join(lo.FilterMap(sinks, func(sink TraceSink, _ int) (error, bool) {
+		closer, ok := sink.(io.Closer)
+		if !ok {
+			return nil, false
+		}
+		err := closer.Close()
+		return err, err != nil
+	})...)
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONLinesFileTraceSinkClosesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	sink, err := NewJSONLinesFileTraceSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Emit(DecisionTrace{GameID: "game", Turn: 3, ChosenMove: "up"})
+
+	var sa SnakeAgent
+	sa.TraceSink = NewMultiTraceSink(NewMemoryTraceSink(), sink)
+	if err := sa.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace DecisionTrace
+	if err := json.Unmarshal(data, &trace); err != nil || trace.ChosenMove != "up" {
+		t.Errorf("file holds %q, want the emitted trace", data)
+	}
+}
+
+func TestTextTraceSinkLabelsMoveScoresWithAggregator(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	log.SetFlags(0)
+	defer log.SetOutput(os.Stderr)
+	defer log.SetFlags(log.LstdFlags)
+
+	NewTextTraceSink().Emit(DecisionTrace{
+		ConsideredMoves: []string{"left", "up"},
+		Heuristics: []HeuristicTrace{{
+			Name: "space", Weight: 2, Aggregator: "mean",
+			Raw: map[string]float64{"left": 12.25, "up": 3},
+		}},
+	})
+
+	want := "MoveScores for       space, w=2.00, mean: left=  12.2, up=   3.0"
+	if !strings.Contains(out.String(), want+"\n") {
+		t.Errorf("log = %q, want a line %q", out.String(), want)
+	}
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const transpositionLockStripes = 64
+
+type transpositionEntry struct {
+	key   uint64
+	depth int
+	value float64
+	valid bool
+}
+
+// TranspositionTable is a fixed-size, concurrency-safe cache of position
+// values keyed by GameSnapshot.Hash(). Each value is stored with the search
+// depth it was computed at; depth 0 is a plain portfolio evaluation.
+type TranspositionTable struct {
+	entries []transpositionEntry
+	locks   [transpositionLockStripes]sync.Mutex
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+// NewTranspositionTable creates a table holding at most size entries
+func NewTranspositionTable(size int) *TranspositionTable {
+	if size < 1 {
+		size = 1
+	}
+	return &TranspositionTable{entries: make([]transpositionEntry, size)}
+}
+
+func (t *TranspositionTable) slot(key uint64) (int, *sync.Mutex) {
+	i := int(key % uint64(len(t.entries)))
+	return i, &t.locks[i%transpositionLockStripes]
+}
+
+// Get returns the value stored for key if it was searched at least minDepth deep
+func (t *TranspositionTable) Get(key uint64, minDepth int) (float64, bool) {
+	i, lock := t.slot(key)
+	lock.Lock()
+	entry := t.entries[i]
+	lock.Unlock()
+
+	if entry.valid && entry.key == key && entry.depth >= minDepth {
+		t.hits.Add(1)
+		return entry.value, true
+	}
+	t.misses.Add(1)
+	return 0, false
+}
+
+// Put stores a value for key, replacing any other position in its slot but
+// never replacing a deeper result for the same position.
+func (t *TranspositionTable) Put(key uint64, depth int, value float64) {
+	i, lock := t.slot(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	existing := t.entries[i]
+	if existing.valid && existing.key == key && existing.depth > depth {
+		return
+	}
+	t.entries[i] = transpositionEntry{key: key, depth: depth, value: value, valid: true}
+}
+
+// GetAndResetStats returns the number of hits and misses since the last call
+func (t *TranspositionTable) GetAndResetStats() (uint64, uint64) {
+	return t.hits.Swap(0), t.misses.Swap(0)
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestTranspositionTable(t *testing.T) {
+	table := NewTranspositionTable(4)
+	table.Put(1, 2, 10)
+	table.Put(1, 1, 20) // a shallower result never replaces a deeper one
+	table.Put(6, 0, 30)
+
+	tests := []struct {
+		name     string
+		key      uint64
+		minDepth int
+		want     float64
+		found    bool
+	}{
+		{"deep enough", 1, 2, 10, true},
+		{"deeper than needed", 1, 0, 10, true},
+		{"too shallow", 1, 3, 0, false},
+		{"other key", 6, 0, 30, true},
+		{"missing key in a used slot", 5, 0, 0, false},
+		{"missing key", 3, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := table.Get(tt.key, tt.minDepth)
+			if value != tt.want || found != tt.found {
+				t.Errorf("Get = %v, %v, want %v, %v", value, found, tt.want, tt.found)
+			}
+		})
+	}
+	if hits, misses := table.GetAndResetStats(); hits != 3 || misses != 3 {
+		t.Errorf("stats = %d hits, %d misses, want 3, 3", hits, misses)
+	}
+	if hits, misses := table.GetAndResetStats(); hits != 0 || misses != 0 {
+		t.Errorf("stats after reset = %d hits, %d misses, want 0, 0", hits, misses)
+	}
+
+	// A different position in the same slot replaces the entry
+	table.Put(5, 0, 40)
+	if _, found := table.Get(1, 0); found {
+		t.Error("replaced entry is still found")
+	}
+}
+
+func TestSnapshotHash(t *testing.T) {
+	base := func() *client.SnakeRequest {
+		request := testRequest("royale", 7, 7,
+			testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)),
+			testSnake("b", pt(5, 5), pt(5, 6), pt(6, 6)))
+		request.Board.Food = []client.Coord{pt(0, 0), pt(6, 0)}
+		request.Board.Hazards = []client.Coord{pt(0, 6)}
+		return request
+	}
+	tests := []struct {
+		name   string
+		change func(*client.SnakeRequest)
+		same   bool
+	}{
+		{"identical", func(*client.SnakeRequest) {}, true},
+		{"food order", func(r *client.SnakeRequest) { r.Board.Food[0], r.Board.Food[1] = r.Board.Food[1], r.Board.Food[0] }, true},
+		{"snake order", func(r *client.SnakeRequest) {
+			r.Board.Snakes[0], r.Board.Snakes[1] = r.Board.Snakes[1], r.Board.Snakes[0]
+		}, true},
+		{"turn of the same parity", func(r *client.SnakeRequest) { r.Turn += 2 }, true},
+		{"turn parity", func(r *client.SnakeRequest) { r.Turn++ }, false},
+		{"health", func(r *client.SnakeRequest) { r.Board.Snakes[1].Health-- }, false},
+		{"body", func(r *client.SnakeRequest) { r.Board.Snakes[1].Body[2] = pt(4, 6) }, false},
+		{"food", func(r *client.SnakeRequest) { r.Board.Food = r.Board.Food[:1] }, false},
+		{"stacked hazard", func(r *client.SnakeRequest) { r.Board.Hazards = append(r.Board.Hazards, pt(0, 6)) }, false},
+		{"perspective", func(r *client.SnakeRequest) { r.You = r.Board.Snakes[1] }, false},
+	}
+	want := NewGameSnapshot(base()).Hash()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := base()
+			tt.change(request)
+			if got := NewGameSnapshot(request).Hash(); (got == want) != tt.same {
+				t.Errorf("hash equal = %v, want %v", got == want, tt.same)
+			}
+		})
+	}
+}
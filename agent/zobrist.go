@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"hash/fnv"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// Zobrist feature kinds. Each feature of a position maps to a pseudo-random
+// 64 bit key and a position's hash is the XOR of the keys of its features.
+const (
+	zobristBody uint64 = iota + 1
+	zobristHealth
+	zobristFood
+	zobristHazard
+	zobristOddTurn
+	zobristYou
+)
+
+// zobristKey derives the key of a feature with splitmix64, so keys are
+// deterministic and need no precomputed table bounded by board size.
+func zobristKey(kind uint64, values ...uint64) uint64 {
+	h := kind
+	for _, v := range values {
+		h = splitmix64(h ^ v)
+	}
+	return splitmix64(h)
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+func pointKey(p rules.Point) uint64 {
+	return uint64(uint32(p.X))<<32 | uint64(uint32(p.Y))
+}
+
+func idKey(id string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return h.Sum64()
+}
+
+// zobristHash hashes the alive snakes' bodies and health, food, hazards
+// (counting stacked hazards), turn parity and whose perspective the snapshot
+// is from.
+func zobristHash(boardState *rules.BoardState, yourID string) uint64 {
+	hash := zobristKey(zobristYou, idKey(yourID))
+	if boardState.Turn%2 == 1 {
+		hash ^= zobristKey(zobristOddTurn)
+	}
+
+	for _, snake := range boardState.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		id := idKey(snake.ID)
+		hash ^= zobristKey(zobristHealth, id, uint64(snake.Health))
+		for i, p := range snake.Body {
+			hash ^= zobristKey(zobristBody, id, uint64(i), pointKey(p))
+		}
+	}
+
+	for _, p := range boardState.Food {
+		hash ^= zobristKey(zobristFood, pointKey(p))
+	}
+
+	hazardStacks := make(map[rules.Point]uint64, len(boardState.Hazards))
+	for _, p := range boardState.Hazards {
+		hazardStacks[p]++
+		hash ^= zobristKey(zobristHazard, pointKey(p), hazardStacks[p])
+	}
+
+	return hash
+}
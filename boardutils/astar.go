@@ -0,0 +1,124 @@
+package boardutils
+
+import (
+	"container/heap"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// CostFunc returns the cost of entering cell as the steps-th move of a path,
+// and false if the cell cannot be entered. Costs must be at least 1 for
+// ShortestPath to find the cheapest path.
+type CostFunc func(cell agent.Cell, steps int) (int, bool)
+
+// UnitCost costs one per move through cells that are passable next turn
+func UnitCost(cell agent.Cell, _ int) (int, bool) {
+	return 1, cell.IsPassable()
+}
+
+// HealthCost costs the health lost entering each passable cell, so paths
+// avoid hazards unless the detour is longer than the damage
+func HealthCost(cell agent.Cell, _ int) (int, bool) {
+	return cell.HealthCost(), cell.IsPassable()
+}
+
+// TimedUnitCost costs one per move, entering body cells that will have moved
+// off by the time we get there
+func TimedUnitCost(cell agent.Cell, steps int) (int, bool) {
+	return 1, PassableInTime(cell, steps)
+}
+
+// ShortestPath finds the cheapest path from one point to another with A*,
+// using the board topology's distance as the estimate of the remaining cost.
+// It returns the points visited from `from` to `to` inclusive and the total
+// cost, or nil and -1 if `to` cannot be reached.
+func ShortestPath(board *agent.Board, from, to rules.Point, cost CostFunc) ([]rules.Point, int) {
+	inBounds := func(p rules.Point) bool {
+		return p.X >= 0 && p.X < board.Width && p.Y >= 0 && p.Y < board.Height
+	}
+	if !inBounds(from) || !inBounds(to) {
+		return nil, -1
+	}
+
+	size := board.Width * board.Height
+	costs := make([]int, size)
+	steps := make([]int, size)
+	previous := make([]int32, size)
+	for i := range costs {
+		costs[i] = -1
+	}
+
+	estimate := func(p rules.Point) int {
+		return board.Topology.Distance(p, to)
+	}
+
+	start, goal := GridIndex(board, from), GridIndex(board, to)
+	costs[start] = 0
+	previous[start] = -1
+	open := &pathQueue{{index: start, priority: estimate(from)}}
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathNode)
+		i := current.index
+		if i == goal {
+			return reconstructPath(board, previous, goal), costs[goal]
+		}
+		p := GridPoint(board, i)
+		if current.priority > costs[i]+estimate(p) {
+			continue // stale entry, a cheaper route was queued later
+		}
+
+		var buf [4]rules.Point
+		for _, next := range gridNeighbours(board, p, buf[:0]) {
+			stepCost, ok := cost(board.Cells[next.Y][next.X], steps[i]+1)
+			if !ok {
+				continue
+			}
+			j := GridIndex(board, next)
+			if costs[j] >= 0 && costs[j] <= costs[i]+stepCost {
+				continue
+			}
+			costs[j] = costs[i] + stepCost
+			steps[j] = steps[i] + 1
+			previous[j] = int32(i)
+			heap.Push(open, pathNode{index: j, priority: costs[j] + estimate(next)})
+		}
+	}
+
+	return nil, -1
+}
+
+func reconstructPath(board *agent.Board, previous []int32, goal int) []rules.Point {
+	var path []rules.Point
+	for i := goal; i >= 0; i = int(previous[i]) {
+		path = append(path, GridPoint(board, i))
+	}
+	for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+		path[l], path[r] = path[r], path[l]
+	}
+	return path
+}
+
+type pathNode struct {
+	index    int
+	priority int
+}
+
+// pathQueue is a min-heap of nodes by priority, for use with container/heap
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int           { return len(q) }
+func (q pathQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q pathQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *pathQueue) Push(x any) {
+	*q = append(*q, x.(pathNode))
+}
+
+func (q *pathQueue) Pop() any {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
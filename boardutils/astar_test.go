@@ -0,0 +1,88 @@
+package boardutils
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestShortestPath(t *testing.T) {
+	a := testSnake("a", pt(0, 0))
+	loner := testSnake("b", pt(4, 4))
+	// b walls off x=2 below the top row; the wall's foot at (2, 0) moves off after two turns
+	wall := testSnake("b", pt(2, 3), pt(2, 2), pt(2, 1), pt(2, 0), pt(3, 0))
+	board := func(ruleset string, b client.Snake, hazards ...client.Coord) *agent.Board {
+		snapshot := agent.NewGameSnapshot(&client.SnakeRequest{
+			Game: client.Game{ID: "test-game", Ruleset: client.Ruleset{
+				Name:     ruleset,
+				Settings: client.RulesetSettings{HazardDamagePerTurn: 14},
+			}},
+			Turn:  1,
+			Board: client.Board{Width: 5, Height: 5, Snakes: []client.Snake{a, b}, Hazards: hazards},
+			You:   a,
+		})
+		return snapshot.Board()
+	}
+	from := rules.Point{X: 0, Y: 0}
+
+	tests := []struct {
+		name     string
+		board    *agent.Board
+		to       rules.Point
+		cost     CostFunc
+		wantCost int
+		wantPath []rules.Point // checked when set
+	}{
+		{"straight", board("standard", loner), rules.Point{X: 3, Y: 0}, UnitCost, 3,
+			[]rules.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}},
+		{"to itself", board("standard", loner), from, UnitCost, 0, []rules.Point{from}},
+		{"around a wall", board("standard", wall), rules.Point{X: 4, Y: 0}, UnitCost, 4 + 4 + 4, nil},
+		{"through a wall once it moves", board("standard", wall), rules.Point{X: 4, Y: 0}, TimedUnitCost, 4, nil},
+		{"around hazards", board("standard", loner, pt(1, 0), pt(2, 0), pt(3, 0)), rules.Point{X: 4, Y: 0}, HealthCost, 6, nil},
+		{"through a hazard with no way around", board("standard", loner, pt(1, 0), pt(1, 1), pt(1, 2), pt(1, 3), pt(1, 4)), rules.Point{X: 2, Y: 0}, HealthCost, 15 + 1, nil},
+		{"across the wrapped edge", board("wrapped", loner), rules.Point{X: 4, Y: 0}, UnitCost, 1,
+			[]rules.Point{{X: 0, Y: 0}, {X: 4, Y: 0}}},
+		{"unreachable", board("standard", wall), rules.Point{X: 4, Y: 0}, func(cell agent.Cell, _ int) (int, bool) {
+			return 1, cell.IsPassable() && cell.Coordinates().Y < 4
+		}, -1, nil},
+		{"off the board", board("standard", loner), rules.Point{X: 5, Y: 0}, UnitCost, -1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cost := ShortestPath(tt.board, from, tt.to, tt.cost)
+			if cost != tt.wantCost {
+				t.Fatalf("cost = %d, want %d (path %v)", cost, tt.wantCost, path)
+			}
+			if cost < 0 {
+				if path != nil {
+					t.Errorf("path = %v, want nil", path)
+				}
+				return
+			}
+			if tt.wantPath != nil && !slices.Equal(path, tt.wantPath) {
+				t.Errorf("path = %v, want %v", path, tt.wantPath)
+			}
+			if path[0] != from || path[len(path)-1] != tt.to {
+				t.Errorf("path = %v, want it to run from %v to %v", path, from, tt.to)
+			}
+			// Every step is a move and the path costs what was returned
+			total := 0
+			for i := 1; i < len(path); i++ {
+				if tt.board.Topology.Distance(path[i-1], path[i]) != 1 {
+					t.Fatalf("path = %v, step %d is not a move", path, i)
+				}
+				stepCost, ok := tt.cost(tt.board.Cells[path[i].Y][path[i].X], i)
+				if !ok {
+					t.Fatalf("path = %v, step %d enters a blocked cell", path, i)
+				}
+				total += stepCost
+			}
+			if total != cost {
+				t.Errorf("path %v costs %d, want %d", path, total, cost)
+			}
+		})
+	}
+}
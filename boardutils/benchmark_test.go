@@ -0,0 +1,171 @@
+package boardutils
+
+import (
+	"testing"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// The Map* benchmarks run the searches as they were before the pooled
+// distance fields, with a map of visited points and a slice queue, so the
+// two can be compared on the same positions.
+
+func BenchmarkFloodFill(b *testing.B) {
+	for _, position := range benchmarkPositions() {
+		board := position.snapshot.Board()
+		start := position.snapshot.You().Head()
+		b.Run(position.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				FloodFill(board, start, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkMapFloodFill(b *testing.B) {
+	for _, position := range benchmarkPositions() {
+		board := position.snapshot.Board()
+		start := position.snapshot.You().Head()
+		b.Run(position.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mapFloodFill(board, start)
+			}
+		})
+	}
+}
+
+func BenchmarkFindNearest(b *testing.B) {
+	for _, position := range benchmarkPositions() {
+		board := position.snapshot.Board()
+		start := position.snapshot.You().Head()
+		b.Run(position.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				FindNearest(board, start, isFood)
+			}
+		})
+	}
+}
+
+func BenchmarkMapFindNearest(b *testing.B) {
+	for _, position := range benchmarkPositions() {
+		board := position.snapshot.Board()
+		start := position.snapshot.You().Head()
+		b.Run(position.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mapFindNearest(board, start, isFood)
+			}
+		})
+	}
+}
+
+func BenchmarkShortestPath(b *testing.B) {
+	for _, position := range benchmarkPositions() {
+		board := position.snapshot.Board()
+		start := position.snapshot.You().Head()
+		food := position.snapshot.Food()
+		goal := food[len(food)-1]
+		b.Run(position.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ShortestPath(board, start, goal, UnitCost)
+			}
+		})
+	}
+}
+
+func BenchmarkVoronoi(b *testing.B) {
+	for _, position := range benchmarkPositions() {
+		board := position.snapshot.Board()
+		heads := VoronoiHeads(position.snapshot)
+		b.Run(position.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Voronoi(board, heads, TieLengthWins)
+			}
+		})
+	}
+}
+
+func BenchmarkBitboardFloodFill(b *testing.B) {
+	for _, position := range benchmarkPositions() {
+		bitboard := position.snapshot.Bitboard()
+		start := position.snapshot.You().Head()
+		b.Run(position.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bitboard.FloodFill(start, bitboard.Passable())
+			}
+		})
+	}
+}
+
+// The benchmarks only compare like with like if both fills agree
+func TestBitboardFloodFillMatchesBoard(t *testing.T) {
+	for _, position := range benchmarkPositions() {
+		start := position.snapshot.You().Head()
+		want, _ := FloodFill(position.snapshot.Board(), start, nil)
+		bitboard := position.snapshot.Bitboard()
+		if got := bitboard.FloodFill(start, bitboard.Passable()).Count(); got != want {
+			t.Errorf("%s: bitboard reached %d cells, board %d", position.name, got, want)
+		}
+	}
+}
+
+// The map-based references only measure the old searches if they agree with the new ones
+func TestMapSearchesMatch(t *testing.T) {
+	for _, position := range benchmarkPositions() {
+		board := position.snapshot.Board()
+		start := position.snapshot.You().Head()
+		if got, want := mapFloodFill(board, start), first(FloodFill(board, start, nil)); got != want {
+			t.Errorf("%s: map flood fill reached %d cells, pooled %d", position.name, got, want)
+		}
+		_, gotDist := mapFindNearest(board, start, isFood)
+		if _, wantDist := FindNearest(board, start, isFood); gotDist != wantDist {
+			t.Errorf("%s: map nearest food at %d, pooled %d", position.name, gotDist, wantDist)
+		}
+	}
+}
+
+func isFood(cell agent.Cell) bool {
+	return cell.Kind() == agent.CellFood
+}
+
+func first[A, B any](a A, _ B) A {
+	return a
+}
+
+// mapFloodFill counts the cells reachable from start as FloodFill did before
+// the pooled distance fields
+func mapFloodFill(board *agent.Board, start rules.Point) int {
+	visited := map[rules.Point]bool{start: true}
+	queue := board.Cells[start.Y][start.X].PassableNeighbours(board)
+	count := 0
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if pos := current.Coordinates(); !visited[pos] {
+			visited[pos] = true
+			count++
+			queue = append(queue, current.PassableNeighbours(board)...)
+		}
+	}
+	return count
+}
+
+// mapFindNearest is FindNearest as it was before the pooled distance fields
+func mapFindNearest(board *agent.Board, start rules.Point, predicate func(agent.Cell) bool) (agent.Cell, int) {
+	visited := map[rules.Point]bool{start: true}
+	queue := []CellWithDist{{board.Cells[start.Y][start.X], 0}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if predicate(current.Cell) {
+			return current.Cell, current.Dist
+		}
+		for _, neighbor := range current.Cell.PassableNeighbours(board) {
+			if pos := neighbor.Coordinates(); !visited[pos] {
+				visited[pos] = true
+				queue = append(queue, CellWithDist{neighbor, current.Dist + 1})
+			}
+		}
+	}
+	return nil, -1
+}
@@ -0,0 +1,193 @@
+package boardutils
+
+import (
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/samber/lo"
+)
+
+// ChamberAnalysis describes the shape of the free space on a board: the
+// cells that are passable next turn, connected to their free neighbours.
+// Articulation points are the doors whose occupation splits the free space
+// apart, and chambers are the regions left between doors.
+type ChamberAnalysis struct {
+	ArticulationPoints []rules.Point
+	Bridges            [][2]rules.Point
+	// Chambers holds the chamber ID of each cell indexed by GridIndex, or -1
+	// for blocked cells and articulation points
+	Chambers     []int
+	ChamberSizes []int
+
+	free         []bool
+	articulation []bool
+}
+
+// MoveChamber describes the space a move leads into, assuming the cell we
+// enter stays blocked by our body. Entering a door cuts off every side of it
+// but the one we continue into, so the largest side is taken.
+type MoveChamber struct {
+	Move string
+	// Door is set when the move enters an articulation point
+	Door bool
+	// Chamber is the ID of the chamber entered, or for a door the chamber on
+	// its largest side; -1 if that is another door or there is no free space
+	Chamber int
+	// Size counts the cells we can still reach, including the one entered
+	Size int
+	// SharedWith lists the opponents whose heads border the same space
+	SharedWith []string
+}
+
+// AnalyzeChambers finds the articulation points, bridges and chambers of
+// the free space on board
+func AnalyzeChambers(board *agent.Board) ChamberAnalysis {
+	size := board.Width * board.Height
+	analysis := ChamberAnalysis{
+		Chambers:     make([]int, size),
+		free:         make([]bool, size),
+		articulation: make([]bool, size),
+	}
+	for i := range analysis.Chambers {
+		p := GridPoint(board, i)
+		analysis.free[i] = board.Cells[p.Y][p.X].IsPassable()
+		analysis.Chambers[i] = -1
+	}
+
+	// Tarjan's algorithm over each connected region of free space
+	discovered := make([]int, size)
+	low := make([]int, size)
+	timer := 0
+	var visit func(u, parent int)
+	visit = func(u, parent int) {
+		timer++
+		discovered[u], low[u] = timer, timer
+		children := 0
+
+		var buf [4]rules.Point
+		for _, n := range gridNeighbours(board, GridPoint(board, u), buf[:0]) {
+			v := GridIndex(board, n)
+			if !analysis.free[v] {
+				continue
+			}
+			if discovered[v] == 0 {
+				children++
+				visit(v, u)
+				low[u] = min(low[u], low[v])
+				if parent >= 0 && low[v] >= discovered[u] {
+					analysis.articulation[u] = true
+				}
+				if low[v] > discovered[u] {
+					analysis.Bridges = append(analysis.Bridges, [2]rules.Point{GridPoint(board, u), n})
+				}
+			} else if v != parent {
+				low[u] = min(low[u], discovered[v])
+			}
+		}
+
+		if parent < 0 && children > 1 {
+			analysis.articulation[u] = true
+		}
+	}
+	for i := range analysis.free {
+		if analysis.free[i] && discovered[i] == 0 {
+			visit(i, -1)
+		}
+	}
+
+	for i, isDoor := range analysis.articulation {
+		if isDoor {
+			analysis.ArticulationPoints = append(analysis.ArticulationPoints, GridPoint(board, i))
+		}
+	}
+
+	// Label the regions of free space between doors
+	insideChamber := func(cell agent.Cell, _ int) bool {
+		i := GridIndex(board, cell.Coordinates())
+		return analysis.free[i] && !analysis.articulation[i]
+	}
+	for i := range analysis.Chambers {
+		if !analysis.free[i] || analysis.articulation[i] || analysis.Chambers[i] >= 0 {
+			continue
+		}
+		id := len(analysis.ChamberSizes)
+		dist := DistancesFrom(board, GridPoint(board, i), insideChamber)
+		count := 0
+		for j, d := range dist {
+			if d != Unreachable {
+				analysis.Chambers[j] = id
+				count++
+			}
+		}
+		ReleaseDistances(dist)
+		analysis.ChamberSizes = append(analysis.ChamberSizes, count)
+	}
+
+	return analysis
+}
+
+// IsArticulationPoint reports whether occupying p splits the free space apart
+func (a ChamberAnalysis) IsArticulationPoint(board *agent.Board, p rules.Point) bool {
+	return a.articulation[GridIndex(board, p)]
+}
+
+// MoveChambers reports the chamber each of your considered moves enters
+func MoveChambers(snapshot agent.GameSnapshot) []MoveChamber {
+	board := snapshot.Board()
+	analysis := AnalyzeChambers(board)
+	you := snapshot.You()
+
+	return lo.Map(you.ConsideredMoves(), func(move rules.SnakeMove, _ int) MoveChamber {
+		result := MoveChamber{Move: move.Move, Chamber: -1}
+		target := you.MoveTarget(move.Move)
+		if target.X < 0 || target.X >= board.Width || target.Y < 0 || target.Y >= board.Height {
+			return result
+		}
+		targetIndex := GridIndex(board, target)
+		if !analysis.free[targetIndex] {
+			return result
+		}
+		result.Door = analysis.articulation[targetIndex]
+		result.Chamber = analysis.Chambers[targetIndex]
+		result.Size = 1
+
+		// Each side of the entered cell, with the entered cell blocked
+		withoutTarget := func(cell agent.Cell, _ int) bool {
+			i := GridIndex(board, cell.Coordinates())
+			return analysis.free[i] && i != targetIndex
+		}
+		var sides [][]int16
+		defer func() {
+			lo.ForEach(sides, func(dist []int16, _ int) { ReleaseDistances(dist) })
+		}()
+		var best []int16
+		var buf [4]rules.Point
+		for _, n := range gridNeighbours(board, target, buf[:0]) {
+			i := GridIndex(board, n)
+			if !analysis.free[i] || lo.SomeBy(sides, func(dist []int16) bool { return dist[i] != Unreachable }) {
+				continue
+			}
+			dist := DistancesFrom(board, n, withoutTarget)
+			sides = append(sides, dist)
+			sideSize := lo.CountBy(dist, func(d int16) bool { return d != Unreachable })
+			if sideSize+1 > result.Size {
+				result.Size = sideSize + 1
+				best = dist
+				if result.Door {
+					result.Chamber = analysis.Chambers[i]
+				}
+			}
+		}
+		if best == nil {
+			return result
+		}
+
+		result.SharedWith = lo.FilterMap(snapshot.Opponents(), func(opponent agent.SnakeSnapshot, _ int) (string, bool) {
+			var buf [4]rules.Point
+			borders := lo.SomeBy(gridNeighbours(board, opponent.Head(), buf[:0]), func(p rules.Point) bool {
+				return best[GridIndex(board, p)] != Unreachable
+			})
+			return opponent.ID(), borders
+		})
+		return result
+	})
+}
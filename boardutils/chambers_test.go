@@ -0,0 +1,87 @@
+package boardutils
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/samber/lo"
+)
+
+// twoRooms returns two 3x3 rooms joined by a corridor along row 1, walled by
+// the heads of b and c, with our head in the middle of the left room:
+//
+//	. . . b . . .
+//	. a . . . . .
+//	. . . c . . .
+func twoRooms() agent.GameSnapshot {
+	return testSnapshot(7, 3, testSnake("a", pt(1, 1)), testSnake("b", pt(3, 2)), testSnake("c", pt(3, 0)))
+}
+
+func TestAnalyzeChambers(t *testing.T) {
+	board := twoRooms().Board()
+	analysis := AnalyzeChambers(board)
+
+	doors := []rules.Point{{X: 2, Y: 1}, {X: 3, Y: 1}, {X: 4, Y: 1}}
+	if !slices.Equal(analysis.ArticulationPoints, doors) {
+		t.Errorf("articulation points = %v, want %v", analysis.ArticulationPoints, doors)
+	}
+	for _, p := range doors {
+		if !analysis.IsArticulationPoint(board, p) {
+			t.Errorf("IsArticulationPoint(%v) = false", p)
+		}
+	}
+	if analysis.IsArticulationPoint(board, rules.Point{X: 0, Y: 1}) {
+		t.Error("IsArticulationPoint on the room's ring = true")
+	}
+
+	bridges := lo.Map(analysis.Bridges, func(b [2]rules.Point, _ int) [2]rules.Point {
+		return lo.Ternary(b[0].X < b[1].X, b, [2]rules.Point{b[1], b[0]})
+	})
+	slices.SortFunc(bridges, func(a, b [2]rules.Point) int { return a[0].X - b[0].X })
+	wantBridges := [][2]rules.Point{{doors[0], doors[1]}, {doors[1], doors[2]}}
+	if !slices.Equal(bridges, wantBridges) {
+		t.Errorf("bridges = %v, want %v", analysis.Bridges, wantBridges)
+	}
+
+	// The ring around our head, less its door, then the right room less its door
+	if !slices.Equal(analysis.ChamberSizes, []int{7, 8}) {
+		t.Errorf("chamber sizes = %v, want [7 8]", analysis.ChamberSizes)
+	}
+	chambers := []struct {
+		p    rules.Point
+		want int
+	}{
+		{rules.Point{X: 0, Y: 0}, 0},
+		{rules.Point{X: 1, Y: 1}, -1}, // our head
+		{rules.Point{X: 3, Y: 1}, -1}, // a door
+		{rules.Point{X: 6, Y: 2}, 1},
+	}
+	for _, tt := range chambers {
+		if got := analysis.Chambers[GridIndex(board, tt.p)]; got != tt.want {
+			t.Errorf("chamber of %v = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestMoveChambers(t *testing.T) {
+	want := map[string]MoveChamber{
+		// The ring stays connected, so every free cell is still reachable
+		"up":   {Move: "up", Chamber: 0, Size: 18, SharedWith: []string{"b", "c"}},
+		"down": {Move: "down", Chamber: 0, Size: 18, SharedWith: []string{"b", "c"}},
+		"left": {Move: "left", Chamber: 0, Size: 18, SharedWith: []string{"b", "c"}},
+		// Entering the door cuts off the ring, leaving the corridor and the right room
+		"right": {Move: "right", Door: true, Chamber: -1, Size: 1 + 2 + 8, SharedWith: []string{"b", "c"}},
+	}
+	got := MoveChambers(twoRooms())
+	if len(got) != len(want) {
+		t.Fatalf("move chambers = %+v, want %d moves", got, len(want))
+	}
+	for _, chamber := range got {
+		w := want[chamber.Move]
+		if chamber.Door != w.Door || chamber.Chamber != w.Chamber || chamber.Size != w.Size || !slices.Equal(chamber.SharedWith, w.SharedWith) {
+			t.Errorf("%s: chamber = %+v, want %+v", chamber.Move, chamber, w)
+		}
+	}
+}
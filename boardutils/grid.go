@@ -0,0 +1,133 @@
+package boardutils
+
+import (
+	"sync"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// Unreachable is the distance of cells a search could not reach
+const Unreachable int16 = -1
+
+// PassableFunc reports whether a search may enter cell as its dist-th move
+type PassableFunc func(cell agent.Cell, dist int) bool
+
+// Passable enters cells that are passable next turn
+func Passable(cell agent.Cell, _ int) bool {
+	return cell.IsPassable()
+}
+
+// PassableInTime enters body cells that will have moved off by the time we get there
+func PassableInTime(cell agent.Cell, dist int) bool {
+	return cell.TurnsUntilFree() <= dist
+}
+
+// GridIndex returns the index of p in a flat distance field of board
+func GridIndex(board *agent.Board, p rules.Point) int {
+	return p.Y*board.Width + p.X
+}
+
+// GridPoint returns the point at index i of a flat distance field of board
+func GridPoint(board *agent.Board, i int) rules.Point {
+	return rules.Point{X: i % board.Width, Y: i / board.Width}
+}
+
+// DistancesFrom returns the number of moves from start to every cell of the
+// board, indexed by GridIndex, or Unreachable. The start cell itself need not
+// be passable. The slice comes from a pool; pass it to ReleaseDistances once
+// done with it to avoid allocating on the next search.
+func DistancesFrom(board *agent.Board, start rules.Point, passable PassableFunc) []int16 {
+	dist, _ := searchDistances(board, start, passable, nil)
+	return dist
+}
+
+// ReleaseDistances returns a distance field to the pool for reuse
+func ReleaseDistances(dist []int16) {
+	distancePool.put(dist)
+}
+
+// gridDeltas are the moves to a cell's neighbours, in the order searches visit them
+var gridDeltas = [4]rules.Point{{X: 0, Y: 1}, {X: 0, Y: -1}, {X: 1, Y: 0}, {X: -1, Y: 0}}
+
+// gridNeighbours appends the neighbours of p under the board's topology to buf
+func gridNeighbours(board *agent.Board, p rules.Point, buf []rules.Point) []rules.Point {
+	for _, d := range gridDeltas {
+		if next, ok := board.Topology.Step(p, d); ok {
+			buf = append(buf, next)
+		}
+	}
+	return buf
+}
+
+var (
+	distancePool bufferPool[int16]
+	queuePool    bufferPool[int32]
+)
+
+// searchDistances runs a BFS from start, stopping early at the first cell
+// matching stop if given. It returns the distances found so far and the index
+// of the matching cell, or -1.
+func searchDistances(board *agent.Board, start rules.Point, passable PassableFunc, stop func(agent.Cell) bool) ([]int16, int) {
+	size := board.Width * board.Height
+	dist := distancePool.get(size)
+	for i := range dist {
+		dist[i] = Unreachable
+	}
+	if start.X < 0 || start.X >= board.Width || start.Y < 0 || start.Y >= board.Height {
+		return dist, -1
+	}
+
+	queue := queuePool.get(size)[:0]
+	defer func() { queuePool.put(queue) }()
+
+	startIndex := GridIndex(board, start)
+	dist[startIndex] = 0
+	queue = append(queue, int32(startIndex))
+
+	for head := 0; head < len(queue); head++ {
+		i := int(queue[head])
+		p := GridPoint(board, i)
+		if stop != nil && stop(board.Cells[p.Y][p.X]) {
+			return dist, i
+		}
+
+		next := int(dist[i]) + 1
+		var buf [4]rules.Point
+		for _, n := range gridNeighbours(board, p, buf[:0]) {
+			j := GridIndex(board, n)
+			if dist[j] != Unreachable || !passable(board.Cells[n.Y][n.X], next) {
+				continue
+			}
+			dist[j] = int16(next)
+			queue = append(queue, int32(j))
+		}
+	}
+
+	return dist, -1
+}
+
+// bufferPool recycles slices so repeated searches don't allocate
+type bufferPool[T any] struct {
+	mu   sync.Mutex
+	free [][]T
+}
+
+func (p *bufferPool[T]) get(size int) []T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.free) > 0 {
+		buf := p.free[len(p.free)-1]
+		p.free = p.free[:len(p.free)-1]
+		if cap(buf) >= size {
+			return buf[:size]
+		}
+	}
+	return make([]T, size)
+}
+
+func (p *bufferPool[T]) put(buf []T) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.free = append(p.free, buf)
+}
@@ -0,0 +1,80 @@
+package boardutils
+
+import (
+	"fmt"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// pt is shorthand for a board coordinate
+func pt(x, y int) client.Coord {
+	return client.Coord{X: x, Y: y}
+}
+
+// testSnake returns a snake at full health whose color is its ID, so every
+// test snake is on its own team unless the test recolors it
+func testSnake(id string, body ...client.Coord) client.Snake {
+	return client.Snake{
+		ID:             id,
+		Name:           id,
+		Health:         100,
+		Body:           body,
+		Head:           body[0],
+		Length:         len(body),
+		Customizations: client.Customizations{Color: id},
+	}
+}
+
+// testSnapshot returns a standard game seen by the first snake
+func testSnapshot(width, height int, snakes ...client.Snake) agent.GameSnapshot {
+	return testSnapshotWithRuleset("standard", width, height, snakes...)
+}
+
+func testSnapshotWithRuleset(ruleset string, width, height int, snakes ...client.Snake) agent.GameSnapshot {
+	return agent.NewGameSnapshot(&client.SnakeRequest{
+		Game:  client.Game{ID: "test-game", Ruleset: client.Ruleset{Name: ruleset}},
+		Turn:  1,
+		Board: client.Board{Width: width, Height: height, Snakes: snakes},
+		You:   snakes[0],
+	})
+}
+
+// benchmarkPosition is a mid-game board shared by the search benchmarks
+type benchmarkPosition struct {
+	name     string
+	snapshot agent.GameSnapshot
+}
+
+// benchmarkPositions returns boards of growing size crossed by snakes that
+// snake up from the bottom edge in two-wide columns, with food scattered
+// above them
+func benchmarkPositions() []benchmarkPosition {
+	position := func(size, snakeCount, length int) benchmarkPosition {
+		snakes := make([]client.Snake, snakeCount)
+		for i := range snakes {
+			x := 1 + i*(size-2)/snakeCount
+			var body []client.Coord
+			for j := 0; j < length; j++ {
+				y := j / 2
+				body = append([]client.Coord{pt(x+((j%2)^(y%2)), y)}, body...)
+			}
+			snakes[i] = testSnake(fmt.Sprint("s", i), body...)
+		}
+		request := &client.SnakeRequest{
+			Game:  client.Game{ID: "benchmark", Ruleset: client.Ruleset{Name: "standard"}},
+			Turn:  50,
+			Board: client.Board{Width: size, Height: size, Snakes: snakes},
+			You:   snakes[0],
+		}
+		for x := 0; x < size; x += 3 {
+			request.Board.Food = append(request.Board.Food, pt(x, size-1-x%5))
+		}
+		return benchmarkPosition{fmt.Sprintf("%dx%d-%dsnakes", size, size, snakeCount), agent.NewGameSnapshot(request)}
+	}
+	return []benchmarkPosition{
+		position(11, 2, 10),
+		position(19, 4, 20),
+		position(25, 8, 30),
+	}
+}
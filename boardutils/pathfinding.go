@@ -11,60 +11,45 @@ type CellWithDist struct {
 	Dist int
 }
 
+// FindNearest returns the nearest cell matching predicate reachable through
+// passable cells, and its distance, or nil and -1 if there is none
 func FindNearest(board *agent.Board, start rules.Point, predicate func(agent.Cell) bool) (agent.Cell, int) {
-	visited := make(map[rules.Point]bool)
-	queue := []CellWithDist{{board.Cells[start.Y][start.X], 0}}
-	visited[start] = true
+	dist, found := searchDistances(board, start, Passable, predicate)
+	defer ReleaseDistances(dist)
 
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
-		if predicate(current.Cell) {
-			return current.Cell, current.Dist
-		}
-
-		for _, neighbor := range current.Cell.PassableNeighbours(board) {
-			pos := neighbor.Coordinates()
-			if !visited[pos] {
-				visited[pos] = true
-				queue = append(queue, CellWithDist{neighbor, current.Dist + 1})
-			}
-		}
+	if found < 0 {
+		return nil, -1
 	}
-
-	return nil, -1
+	p := GridPoint(board, found)
+	return board.Cells[p.Y][p.X], int(dist[found])
 }
 
 // FloodFill returns the count of reachable cells and whether a target position is reachable
 func FloodFill(board *agent.Board, start rules.Point, target *rules.Point) (int, bool) {
-	visited := make(map[rules.Point]bool)
-	queue := board.Cells[start.Y][start.X].PassableNeighbours(board)
-	count := 0
-	targetFound := false
-
-	// Mark start as visited to prevent backtracking
-	visited[start] = true
+	return countReachable(board, start, target, Passable)
+}
 
-	// Process all cells including initial neighbors
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-		
-		pos := current.Coordinates()
-		if !visited[pos] {
-			visited[pos] = true
-			count++
+// TimedFloodFill is like FloodFill but accounts for snake bodies moving: a body
+// cell reached at distance d counts as passable if that segment will have
+// moved off by the time we get there. Returns the count of reachable cells and
+// whether the target position is reachable.
+func TimedFloodFill(board *agent.Board, start rules.Point, target *rules.Point) (int, bool) {
+	return countReachable(board, start, target, PassableInTime)
+}
 
-			// Check if this is the target position
-			if target != nil && pos.X == target.X && pos.Y == target.Y {
-				targetFound = true
-			}
+func countReachable(board *agent.Board, start rules.Point, target *rules.Point, passable PassableFunc) (int, bool) {
+	dist := DistancesFrom(board, start, passable)
+	defer ReleaseDistances(dist)
 
-			// Add unvisited passable neighbors
-			queue = append(queue, current.PassableNeighbours(board)...)
+	count := 0
+	for _, d := range dist {
+		if d > 0 {
+			count++
 		}
 	}
 
+	targetFound := target != nil &&
+		target.X >= 0 && target.X < board.Width && target.Y >= 0 && target.Y < board.Height &&
+		dist[GridIndex(board, *target)] > 0
 	return count, targetFound
 }
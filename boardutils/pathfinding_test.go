@@ -0,0 +1,38 @@
+package boardutils
+
+import (
+	"testing"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+func TestTimedFloodFill(t *testing.T) {
+	// b walls off x=1 with its tail stacked at (1, 0) after eating, so the
+	// only way through is the tail once it has moved off, two turns from now
+	snapshot := testSnapshot(4, 3,
+		testSnake("a", pt(0, 2)),
+		testSnake("b", pt(1, 2), pt(1, 1), pt(1, 0), pt(1, 0)))
+	board := snapshot.Board()
+	target := rules.Point{X: 3, Y: 0}
+
+	tests := []struct {
+		name      string
+		start     rules.Point
+		fill      func(*agent.Board, rules.Point, *rules.Point) (int, bool)
+		wantCount int
+		wantFound bool
+	}{
+		{"untimed stops at the tail", rules.Point{X: 0, Y: 2}, FloodFill, 2, false},
+		{"timed passes the tail once it moves off", rules.Point{X: 0, Y: 2}, TimedFloodFill, 4*3 - 1, true},
+		{"timed arrives before the tail moves off", rules.Point{X: 0, Y: 0}, TimedFloodFill, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, found := tt.fill(board, tt.start, &target)
+			if count != tt.wantCount || found != tt.wantFound {
+				t.Errorf("fill = %d, %v, want %d, %v", count, found, tt.wantCount, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package boardutils
+
+import (
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// HeadThreats maps every passable cell that the head of a snake outside our
+// team could move into next turn to the snakes that could move there, with
+// their lengths. A move into a cell threatened by a snake at least as long
+// as ours is contested, which is how ConsideredMoves applies the agent's
+// head-to-head policy.
+func HeadThreats(snapshot agent.GameSnapshot) map[rules.Point][]agent.HeadThreat {
+	return agent.HeadThreatsTo(snapshot, snapshot.Team(snapshot.You().ID()))
+}
@@ -0,0 +1,41 @@
+package boardutils
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+func TestHeadThreats(t *testing.T) {
+	// b and d share (5, 4), b's body blocks (6, 3) but d can follow its tail
+	// to (5, 6), and c is our ally
+	//
+	//	. . . . . d .
+	//	. . . . . d .
+	//	. c a . . b b
+	//	. . . a . . b
+	c := testSnake("c", pt(1, 3), pt(0, 3))
+	c.Customizations.Color = "a"
+	snapshot := testSnapshot(7, 7,
+		testSnake("a", pt(3, 3), pt(3, 2), pt(3, 1)),
+		testSnake("b", pt(5, 3), pt(6, 3), pt(6, 2), pt(6, 1)),
+		c,
+		testSnake("d", pt(5, 5), pt(5, 6)))
+
+	b := agent.HeadThreat{SnakeID: "b", Length: 4}
+	d := agent.HeadThreat{SnakeID: "d", Length: 2}
+	want := map[rules.Point][]agent.HeadThreat{
+		{X: 4, Y: 3}: {b},
+		{X: 5, Y: 2}: {b},
+		{X: 5, Y: 4}: {b, d},
+		{X: 4, Y: 5}: {d},
+		{X: 6, Y: 5}: {d},
+		{X: 5, Y: 6}: {d},
+	}
+	if got := HeadThreats(snapshot); !maps.EqualFunc(got, want, slices.Equal[[]agent.HeadThreat]) {
+		t.Errorf("threats = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package boardutils
+
+import (
+	"slices"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/samber/lo"
+)
+
+// TieRule decides who owns a cell that several snakes reach on the same turn
+type TieRule int
+
+const (
+	// TieLengthWins gives the cell to the strictly longest snake, leaving it
+	// contested if the longest snakes are equally long
+	TieLengthWins TieRule = iota
+	// TieContested leaves every tied cell contested
+	TieContested
+)
+
+// VoronoiHead is a snake taking part in the territory split
+type VoronoiHead struct {
+	SnakeID string
+	Team    string
+	Head    rules.Point
+	Length  int
+}
+
+// Territory counts the cells, and the food among them, that one snake or team reaches first
+type Territory struct {
+	Cells int
+	Food  int
+	// FoodDistances holds the turns the owner needs to reach each food, nearest first
+	FoodDistances []int
+}
+
+type VoronoiResult struct {
+	// Owners holds the ID of the snake owning each cell, indexed [y][x]; it is
+	// empty for unreachable and contested cells. Heads are not owned.
+	Owners    [][]string
+	Snakes    map[string]Territory
+	Teams     map[string]Territory
+	Contested int
+}
+
+// VoronoiHeads returns the heads of every alive snake with their team keys
+func VoronoiHeads(snapshot agent.GameSnapshot) []VoronoiHead {
+	return lo.Map(snapshot.AliveSnakes(), func(snake agent.SnakeSnapshot, _ int) VoronoiHead {
+		return VoronoiHead{
+			SnakeID: snake.ID(),
+			Team:    snapshot.Team(snake.ID()),
+			Head:    snake.Head(),
+			Length:  snake.Length(),
+		}
+	})
+}
+
+// Voronoi splits the board between the given snakes using each head's
+// distance field, so each cell goes to the snake that can reach it first.
+// Like TimedFloodFill, a body cell can be entered once it will have moved off.
+// A contested cell reached only by teammates still counts for their team.
+func Voronoi(board *agent.Board, heads []VoronoiHead, tie TieRule) VoronoiResult {
+	result := VoronoiResult{
+		Owners: make([][]string, board.Height),
+		Snakes: make(map[string]Territory, len(heads)),
+		Teams:  make(map[string]Territory),
+	}
+	for y := range result.Owners {
+		result.Owners[y] = make([]string, board.Width)
+	}
+
+	fields := make([][]int16, len(heads))
+	for i, head := range heads {
+		result.Snakes[head.SnakeID] = Territory{}
+		result.Teams[head.Team] = Territory{}
+		fields[i] = DistancesFrom(board, head.Head, PassableInTime)
+	}
+	defer func() {
+		for _, dist := range fields {
+			ReleaseDistances(dist)
+		}
+	}()
+
+	claim := func(territories map[string]Territory, key string, reached CellWithDist) {
+		t := territories[key]
+		t.Cells++
+		if reached.Cell.Kind() == agent.CellFood {
+			t.Food++
+			t.FoodDistances = append(t.FoodDistances, reached.Dist)
+		}
+		territories[key] = t
+	}
+
+	claimants := make([]int, 0, len(heads))
+	for i := 0; i < board.Width*board.Height; i++ {
+		// Collect every snake reaching the cell on the earliest turn
+		best := Unreachable
+		claimants = claimants[:0]
+		for h, dist := range fields {
+			if dist[i] == Unreachable || (best != Unreachable && dist[i] > best) {
+				continue
+			}
+			if dist[i] != best {
+				best = dist[i]
+				claimants = claimants[:0]
+			}
+			claimants = append(claimants, h)
+		}
+		// Heads are the only cells at distance 0 and stay unowned
+		if best == Unreachable || best == 0 {
+			continue
+		}
+
+		pos := GridPoint(board, i)
+		reached := CellWithDist{board.Cells[pos.Y][pos.X], int(best)}
+		owner, contested := resolveTie(heads, claimants, tie)
+		if contested {
+			result.Contested++
+			team := heads[claimants[0]].Team
+			if lo.EveryBy(claimants, func(i int) bool { return heads[i].Team == team }) {
+				claim(result.Teams, team, reached)
+			}
+			continue
+		}
+
+		head := heads[owner]
+		result.Owners[pos.Y][pos.X] = head.SnakeID
+		claim(result.Snakes, head.SnakeID, reached)
+		claim(result.Teams, head.Team, reached)
+	}
+
+	for _, territories := range []map[string]Territory{result.Snakes, result.Teams} {
+		for _, t := range territories {
+			slices.Sort(t.FoodDistances)
+		}
+	}
+	return result
+}
+
+// resolveTie returns the index of the head that owns a cell reached by the
+// given heads on the same turn, or reports that the cell is contested
+func resolveTie(heads []VoronoiHead, claimants []int, tie TieRule) (int, bool) {
+	if len(claimants) == 1 {
+		return claimants[0], false
+	}
+	if tie == TieContested {
+		return -1, true
+	}
+
+	longest := lo.MaxBy(claimants, func(a, b int) bool {
+		return heads[a].Length > heads[b].Length
+	})
+	if lo.CountBy(claimants, func(i int) bool { return heads[i].Length == heads[longest].Length }) > 1 {
+		return -1, true
+	}
+	return longest, false
+}
@@ -0,0 +1,90 @@
+package boardutils
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/samber/lo"
+)
+
+// corridorSnapshot returns a 7x1 board with a at x=0 and b at x=6, each
+// stacked to the given length, and food at x=2
+func corridorSnapshot(aLength, bLength int, bColor string) agent.GameSnapshot {
+	stacked := func(id string, x, length int) client.Snake {
+		return testSnake(id, lo.Times(length, func(int) client.Coord { return pt(x, 0) })...)
+	}
+	b := stacked("b", 6, bLength)
+	b.Customizations.Color = bColor
+	return agent.NewGameSnapshot(&client.SnakeRequest{
+		Game:  client.Game{ID: "test-game", Ruleset: client.Ruleset{Name: "standard"}},
+		Turn:  1,
+		Board: client.Board{Width: 7, Height: 1, Snakes: []client.Snake{stacked("a", 0, aLength), b}, Food: []client.Coord{pt(2, 0)}},
+		You:   stacked("a", 0, aLength),
+	})
+}
+
+func TestVoronoi(t *testing.T) {
+	tests := []struct {
+		name       string
+		snapshot   agent.GameSnapshot
+		tie        TieRule
+		owners     []string
+		a, b, team Territory // team is a's team
+		contested  int
+	}{
+		{"equal lengths contest the middle", corridorSnapshot(2, 2, "b"), TieLengthWins,
+			[]string{"", "a", "a", "", "b", "b", ""},
+			Territory{Cells: 2, Food: 1, FoodDistances: []int{2}}, Territory{Cells: 2}, Territory{Cells: 2, Food: 1, FoodDistances: []int{2}}, 1},
+		{"longer snake wins the tie", corridorSnapshot(3, 2, "b"), TieLengthWins,
+			[]string{"", "a", "a", "a", "b", "b", ""},
+			Territory{Cells: 3, Food: 1, FoodDistances: []int{2}}, Territory{Cells: 2}, Territory{Cells: 3, Food: 1, FoodDistances: []int{2}}, 0},
+		{"every tie contested", corridorSnapshot(3, 2, "b"), TieContested,
+			[]string{"", "a", "a", "", "b", "b", ""},
+			Territory{Cells: 2, Food: 1, FoodDistances: []int{2}}, Territory{Cells: 2}, Territory{Cells: 2, Food: 1, FoodDistances: []int{2}}, 1},
+		{"teammates share a contested cell", corridorSnapshot(2, 2, "a"), TieLengthWins,
+			[]string{"", "a", "a", "", "b", "b", ""},
+			Territory{Cells: 2, Food: 1, FoodDistances: []int{2}}, Territory{Cells: 2}, Territory{Cells: 5, Food: 1, FoodDistances: []int{2}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Voronoi(tt.snapshot.Board(), VoronoiHeads(tt.snapshot), tt.tie)
+			if !slices.Equal(result.Owners[0], tt.owners) {
+				t.Errorf("owners = %q, want %q", result.Owners[0], tt.owners)
+			}
+			for name, got := range map[string]Territory{"a": result.Snakes["a"], "b": result.Snakes["b"], "team": result.Teams[tt.snapshot.Team("a")]} {
+				want := map[string]Territory{"a": tt.a, "b": tt.b, "team": tt.team}[name]
+				if got.Cells != want.Cells || got.Food != want.Food || !slices.Equal(got.FoodDistances, want.FoodDistances) {
+					t.Errorf("%s territory = %+v, want %+v", name, got, want)
+				}
+			}
+			if result.Contested != tt.contested {
+				t.Errorf("contested = %d, want %d", result.Contested, tt.contested)
+			}
+		})
+	}
+}
+
+func TestVoronoiEntersBodyOnceItMoves(t *testing.T) {
+	// b's body walls off x=3 of a 7x3 board. a, alone in the split, gets
+	// through if the wall has moved off by the time a reaches it.
+	a := testSnake("a", pt(0, 0))
+	tests := []struct {
+		name string
+		b    client.Snake
+		want int
+	}{
+		{"short wall", testSnake("b", pt(3, 2), pt(3, 1), pt(3, 0)), 7*3 - 1},
+		{"long wall", testSnake("b", pt(3, 2), pt(3, 1), pt(3, 0), pt(4, 0), pt(5, 0), pt(6, 0)), 3*3 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := testSnapshot(7, 3, a, tt.b)
+			heads := lo.Filter(VoronoiHeads(snapshot), func(h VoronoiHead, _ int) bool { return h.SnakeID == "a" })
+			if got := Voronoi(snapshot.Board(), heads, TieLengthWins).Snakes["a"].Cells; got != tt.want {
+				t.Errorf("a owns %d cells, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,7 +14,7 @@ func HeuristicSpace(snapshot agent.GameSnapshot) float64 {
 	
 	// Check if we can reach our tail
 	tail := snake.Body()[len(snake.Body())-1]
-	spaces, tailReachable := boardutils.FloodFill(board, head, &tail)
+	spaces, tailReachable := boardutils.TimedFloodFill(board, head, &tail)
 	
 	// log.Printf("Spaces available: %d, Tail reachable: %t", spaces, tailReachable)
 	
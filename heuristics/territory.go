@@ -0,0 +1,41 @@
+package heuristics
+
+import (
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/Battle-Bunker/cyphid-snake/boardutils"
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// HeuristicTerritory scores the territory held by your team minus the
+// territory held by its opponents. Each cell is owned by the snake whose head
+// reaches it first, and each snake's territory is valued as the number of
+// turns it could survive there.
+func HeuristicTerritory(snapshot agent.GameSnapshot) float64 {
+	voronoi := boardutils.Voronoi(snapshot.Board(), boardutils.VoronoiHeads(snapshot), boardutils.TieLengthWins)
+
+	teamTurns := func(snakes []agent.SnakeSnapshot) float64 {
+		total := 0
+		for _, snake := range snakes {
+			if snake.Alive() {
+				total += turnsSurvivable(snake.Health(), voronoi.Snakes[snake.ID()])
+			}
+		}
+		return float64(total)
+	}
+
+	return teamTurns(snapshot.YourTeam()) - teamTurns(snapshot.Opponents())
+}
+
+// turnsSurvivable is how long a snake with the given health lives in its
+// territory. It eats each food it reaches before starving, which refills its
+// health on arrival, and it cannot outlast the territory's cells.
+func turnsSurvivable(health int, territory boardutils.Territory) int {
+	turns := health
+	for _, distance := range territory.FoodDistances {
+		if distance > turns {
+			break
+		}
+		turns = distance + rules.SnakeMaxHealth
+	}
+	return min(turns, territory.Cells)
+}
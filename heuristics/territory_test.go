@@ -0,0 +1,71 @@
+package heuristics
+
+import (
+	"testing"
+
+	"github.com/Battle-Bunker/cyphid-snake/agent"
+	"github.com/Battle-Bunker/cyphid-snake/boardutils"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// corridor returns an 11x1 board where "a" holds the four cells x=0..4 and
+// "b" the four cells x=6..10, with food at (4, 0) three turns from a
+func corridor(aHealth, bHealth int) agent.GameSnapshot {
+	snake := func(id string, health int, body ...client.Coord) client.Snake {
+		return client.Snake{ID: id, Health: health, Body: body, Head: body[0], Length: len(body),
+			Customizations: client.Customizations{Color: id}}
+	}
+	a := snake("a", aHealth, client.Coord{X: 1, Y: 0}, client.Coord{X: 0, Y: 0})
+	b := snake("b", bHealth, client.Coord{X: 9, Y: 0}, client.Coord{X: 10, Y: 0})
+	return agent.NewGameSnapshot(&client.SnakeRequest{
+		Game: client.Game{ID: "territory", Ruleset: client.Ruleset{Name: "standard"}},
+		Board: client.Board{
+			Width: 11, Height: 1,
+			Snakes: []client.Snake{a, b},
+			Food:   []client.Coord{{X: 4, Y: 0}},
+		},
+		You: a,
+	})
+}
+
+func TestHeuristicTerritory(t *testing.T) {
+	tests := []struct {
+		name             string
+		aHealth, bHealth int
+		want             float64
+	}{
+		{"both outlast their cells", 50, 50, 0},
+		{"food in reach refills", 3, 2, 4 - 2},
+		{"food out of reach", 2, 2, 2 - 2},
+		{"starving opponent", 50, 1, 4 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HeuristicTerritory(corridor(tt.aHealth, tt.bHealth)); got != tt.want {
+				t.Errorf("HeuristicTerritory = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTurnsSurvivable(t *testing.T) {
+	tests := []struct {
+		name      string
+		health    int
+		territory boardutils.Territory
+		want      int
+	}{
+		{"no food", 10, boardutils.Territory{Cells: 50}, 10},
+		{"cells run out first", 10, boardutils.Territory{Cells: 4}, 4},
+		{"food in reach", 10, boardutils.Territory{Cells: 500, FoodDistances: []int{7}}, 107},
+		{"food out of reach", 10, boardutils.Territory{Cells: 500, FoodDistances: []int{11}}, 10},
+		{"chained food", 10, boardutils.Territory{Cells: 500, FoodDistances: []int{5, 90}}, 190},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := turnsSurvivable(tt.health, tt.territory); got != tt.want {
+				t.Errorf("turnsSurvivable = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -26,7 +26,15 @@ func Softmax(inputs []float64) []float64 {
 }
 
 func SampleFromWeights(weights []float64) int {
-		r := rand.Float64()
+		return sampleFromWeights(weights, rand.Float64())
+}
+
+// SampleFromWeightsWithRand samples an index like SampleFromWeights, drawing from rng
+func SampleFromWeightsWithRand(weights []float64, rng *rand.Rand) int {
+		return sampleFromWeights(weights, rng.Float64())
+}
+
+func sampleFromWeights(weights []float64, r float64) int {
 		var cumulativeProb float64
 		for i, weight := range weights {
 			cumulativeProb += weight
@@ -41,3 +49,17 @@ func SoftmaxSample(inputs []float64) int {
 		probs := Softmax(inputs)
 		return SampleFromWeights(probs)
 }
+
+// WeightedMean returns the mean of values weighted by weights, or the plain
+// mean when the weights sum to zero.
+func WeightedMean(values []float64, weights []float64) float64 {
+	totalWeight := lo.Sum(weights)
+	if totalWeight == 0 {
+		return lo.Mean(values)
+	}
+	sum := 0.0
+	for i, v := range values {
+		sum += v * weights[i]
+	}
+	return sum / totalWeight
+}
@@ -0,0 +1,93 @@
+package lib
+
+import (
+	"math"
+	"sort"
+
+	"github.com/samber/lo"
+)
+
+type weightedValue struct {
+	value  float64
+	weight float64
+}
+
+// sortedByValue pairs values with their weights, dropping zero weights, and
+// sorts them ascending. When every weight is zero all values count equally.
+func sortedByValue(values []float64, weights []float64) ([]weightedValue, float64) {
+	totalWeight := lo.Sum(weights)
+	pairs := make([]weightedValue, 0, len(values))
+	for i, v := range values {
+		w := 1.0
+		if totalWeight > 0 {
+			w = weights[i]
+		}
+		if w > 0 {
+			pairs = append(pairs, weightedValue{v, w})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].value < pairs[j].value })
+	return pairs, lo.SumBy(pairs, func(p weightedValue) float64 { return p.weight })
+}
+
+// WeightedMin returns the smallest value with a non-zero weight
+func WeightedMin(values []float64, weights []float64) float64 {
+	pairs, _ := sortedByValue(values, weights)
+	if len(pairs) == 0 {
+		return math.NaN()
+	}
+	return pairs[0].value
+}
+
+// WeightedPercentile returns the smallest value at or below which at least
+// fraction p (0..1) of the total weight lies.
+func WeightedPercentile(values []float64, weights []float64, p float64) float64 {
+	pairs, totalWeight := sortedByValue(values, weights)
+	if len(pairs) == 0 {
+		return math.NaN()
+	}
+	cumulative := 0.0
+	for _, pair := range pairs {
+		cumulative += pair.weight
+		if cumulative >= p*totalWeight {
+			return pair.value
+		}
+	}
+	return pairs[len(pairs)-1].value
+}
+
+// WeightedCVaR returns the conditional value at risk: the weighted mean of the
+// worst fraction alpha (0..1] of the distribution.
+func WeightedCVaR(values []float64, weights []float64, alpha float64) float64 {
+	pairs, totalWeight := sortedByValue(values, weights)
+	if len(pairs) == 0 {
+		return math.NaN()
+	}
+	tailWeight := alpha * totalWeight
+	if tailWeight <= 0 {
+		return pairs[0].value
+	}
+	sum, cumulative := 0.0, 0.0
+	for _, pair := range pairs {
+		w := math.Min(pair.weight, tailWeight-cumulative)
+		sum += pair.value * w
+		cumulative += w
+		if cumulative >= tailWeight {
+			break
+		}
+	}
+	return sum / cumulative
+}
+
+// WeightedStdDev returns the weighted population standard deviation
+func WeightedStdDev(values []float64, weights []float64) float64 {
+	pairs, totalWeight := sortedByValue(values, weights)
+	if len(pairs) == 0 {
+		return math.NaN()
+	}
+	mean := lo.SumBy(pairs, func(p weightedValue) float64 { return p.value * p.weight }) / totalWeight
+	variance := lo.SumBy(pairs, func(p weightedValue) float64 {
+		return p.weight * (p.value - mean) * (p.value - mean)
+	}) / totalWeight
+	return math.Sqrt(variance)
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"log"
+	"os"
+
 	"github.com/Battle-Bunker/cyphid-snake/agent"
 	"github.com/Battle-Bunker/cyphid-snake/server"
 	"github.com/BattlesnakeOfficial/rules/client"
@@ -24,8 +27,26 @@ func main() {
 
 	snakeAgent := agent.NewSnakeAgent(portfolio, metadata, 
 		agent.WithTemperature(5.0),
-		agent.WithPerformanceLogging(true))
+		agent.WithPerformanceLogging(true),
+		agent.WithTeamResolver(teamResolverFromEnv()))
 	server := server.NewServer(snakeAgent)
 
 	server.Start()
 }
+
+// teamResolverFromEnv picks how allies are recognised: by the allow-list file
+// in TEAM_ALLOW_LIST, by shout handshakes signed with TEAM_HANDSHAKE_SECRET,
+// or else by color
+func teamResolverFromEnv() agent.TeamResolver {
+	if path := os.Getenv("TEAM_ALLOW_LIST"); path != "" {
+		resolver, err := agent.LoadAllowListTeamResolver(path)
+		if err != nil {
+			log.Fatalf("Error loading team allow-list: %v", err)
+		}
+		return resolver
+	}
+	if secret := os.Getenv("TEAM_HANDSHAKE_SECRET"); secret != "" {
+		return agent.NewShoutHandshakeTeamResolver([]byte(secret))
+	}
+	return agent.NewColorTeamResolver()
+}
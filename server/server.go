@@ -3,13 +3,17 @@ package server
 import (
 	"github.com/Battle-Bunker/cyphid-snake/agent"
 	"github.com/BattlesnakeOfficial/rules/client"
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	// "io"
 	// "bytes"
 	"os"
+	"os/signal"
 	"io"
+	"syscall"
+	"time"
 )
 
 type Server struct {
@@ -43,10 +47,34 @@ func (s *Server) Start() {
 	http.HandleFunc("/move", withServerID(s.handleMove))
 	http.HandleFunc("/end", withServerID(s.handleEnd))
 
+	// On an interrupt, finish the moves in flight and then close the agent
+	server := &http.Server{Addr: ":" + port}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down server: %v", err)
+		}
+	}()
+
 	log.Printf("Running Battlesnake at http://0.0.0.0:%s...\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+	<-stopped
+	if err := s.agent.Close(); err != nil {
+		log.Printf("Error closing agent: %v", err)
+	}
 }
 
+// shutdownTimeout bounds how long an interrupted server waits for moves in flight
+const shutdownTimeout = 5 * time.Second
+
 
 func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
 	log.Println("START")
@@ -54,6 +82,7 @@ func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
+	received := time.Now()
 	// log.Printf("Received move request with Content-Length: %d", r.ContentLength)
 
 	if r.Body == nil {
@@ -88,7 +117,7 @@ func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var gameSnapshot agent.GameSnapshot
-	if gameSnapshot = agent.NewGameSnapshot(&request); gameSnapshot == nil {
+	if gameSnapshot = s.agent.NewGameSnapshot(&request); gameSnapshot == nil {
 		log.Printf("Error creating game snapshot")
 				w.WriteHeader(http.StatusInternalServerError)
 				response := map[string]string{"error": "unable to create game snapshot"}
@@ -96,7 +125,10 @@ func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	moveResponse := s.agent.ChooseMove(gameSnapshot)
+	ctx, cancel := context.WithDeadline(r.Context(), s.agent.MoveDeadline(received, request.Game.Timeout))
+	defer cancel()
+
+	moveResponse := s.agent.ChooseMoveWithContext(ctx, gameSnapshot)
 	log.Printf("Turn %d: Move %s, Shout '%s'", request.Turn, moveResponse.Move, moveResponse.Shout)
 	
 	w.Header().Set("Content-Type", "application/json")
@@ -109,6 +141,17 @@ func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleEnd(w http.ResponseWriter, r *http.Request) {
 	log.Println("END")
+
+	var request client.SnakeRequest
+	if r.Body != nil {
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			log.Printf("Error decoding end request: %v", err)
+		} else {
+			s.agent.EndGame(request.Game.ID)
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
 